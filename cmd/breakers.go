@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+)
+
+func breakers(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path,
+		"Run a command with the circuit breakers enabled, then print every breaker with its\nstate, counts and last transition, e.g.\n\n  "+path+" -force-open /bank.BankService/TransferMultiple resiliency unary-breaker\n\nKeys are full method names, or services or targets depending on breaker.key.")
+	forceOpen := fs.String("force-open", "", "comma separated breaker keys to reject calls for")
+	forceClosed := fs.String("force-closed", "", "comma separated breaker keys to let all calls through")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errHelp
+		}
+		return nil, usageErrorf("%v: %v", path, err)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return nil, usageErrorf("%v: missing command", path)
+	}
+
+	if err := app.enableBreakers(); err != nil {
+		return nil, err
+	}
+	if _, err := app.clientConn(); err != nil {
+		return nil, err
+	}
+	for _, key := range splitList(*forceOpen) {
+		app.breakers.ForceOpen(key)
+	}
+	for _, key := range splitList(*forceClosed) {
+		app.breakers.ForceClosed(key)
+	}
+
+	inner, err := rootCommand().resolve(app, programName, fs.Args())
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		err := inner(ctx)
+		printBreakers(os.Stdout, app.breakers.Breakers())
+		return err
+	}, nil
+}
+
+func printBreakers(w io.Writer, breakers []interceptor.BreakerStatus) {
+	if len(breakers) == 0 {
+		fmt.Fprintln(w, "No circuit breaker was used.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tSTATE\tTRIPS\tREQUESTS\tSUCCESSES\tFAILURES\tCONSECUTIVE FAILURES\tLAST TRANSITION")
+	for _, b := range breakers {
+		state := b.State.String()
+		if b.Forced {
+			state += " (forced)"
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			b.Key, state, b.Trips, b.Counts.Requests, b.Counts.TotalSuccesses, b.Counts.TotalFailures,
+			b.Counts.ConsecutiveFailures, b.LastTransition.Format(time.RFC3339))
+	}
+	tw.Flush()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/deadline"
+)
+
+func chain(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path,
+		"Get the balance of an account, then call UnaryResiliency. The two calls share one\n-timeout: each gets an equal part of what is left when it starts, minus -reserve.")
+	acct := fs.String("account", "", "account number")
+	timeout := fs.Duration("timeout", 5*time.Second, "deadline for both calls")
+	reserve := fs.Duration("reserve", 100*time.Millisecond, "time kept back after the last call")
+	f := newResiliencyFlags(fs)
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "account", *acct); err != nil {
+		return nil, err
+	}
+
+	bankAdapter, err := app.bankAdapter()
+	if err != nil {
+		return nil, err
+	}
+	resiliencyAdapter, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+		budget := deadline.New(ctx, 2, *reserve)
+
+		callCtx, cancelCall, err := budget.Next(ctx)
+		if err != nil {
+			return err
+		}
+		bal, err := bankAdapter.GetCurrentBalance(callCtx, *acct)
+		cancelCall()
+		if err != nil {
+			return err
+		}
+		log.Println("Current balance: ", bal)
+
+		callCtx, cancelCall, err = budget.Next(ctx)
+		if err != nil {
+			return err
+		}
+		defer cancelCall()
+		resp, err := resiliencyAdapter.UnaryResiliency(callCtx, f.minDelay, f.maxDelay, f.statusCodes)
+		if err != nil {
+			return err
+		}
+		log.Println(resp.DummyString)
+		return nil
+	}, nil
+}
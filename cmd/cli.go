@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/config"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+var errHelp = errors.New("help requested")
+
+// usageError is returned for bad command lines, it maps to exitUsage.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// call makes the calls of a command, with ctx.
+type call func(ctx context.Context) error
+
+// command is a node of the CLI tree. Groups have children, leaves have
+// build, which parses the flags and dials before returning the call.
+type command struct {
+	name     string
+	short    string
+	children []*command
+	build    func(app *app, path string, args []string) (call, error)
+}
+
+// execute runs the command args select, calls made by it get ctx.
+func (c *command) execute(ctx context.Context, app *app, path string, args []string) error {
+	call, err := c.resolve(app, path, args)
+	if err != nil {
+		return err
+	}
+	return call(ctx)
+}
+
+// resolve returns the call of the command args select, without making it.
+func (c *command) resolve(app *app, path string, args []string) (call, error) {
+	if c.build != nil {
+		return c.build(app, path, args)
+	}
+
+	if len(args) == 0 {
+		c.printUsage(os.Stderr, path)
+		return nil, usageErrorf("missing command for %v", path)
+	}
+	switch args[0] {
+	case "-h", "-help", "--help", "help":
+		c.printUsage(os.Stdout, path)
+		return nil, errHelp
+	}
+
+	for _, child := range c.children {
+		if child.name == args[0] {
+			return child.resolve(app, path+" "+child.name, args[1:])
+		}
+	}
+	c.printUsage(os.Stderr, path)
+	return nil, usageErrorf("unknown command %q for %v", args[0], path)
+}
+
+func (c *command) printUsage(w io.Writer, path string) {
+	fmt.Fprintf(w, "Usage: %v <command> [flags]\n\n", path)
+	if c.short != "" {
+		fmt.Fprintf(w, "%v\n\n", c.short)
+	}
+	fmt.Fprintln(w, "Commands:")
+	for _, child := range c.children {
+		fmt.Fprintf(w, "  %-20v %v\n", child.name, child.short)
+	}
+	fmt.Fprintf(w, "\nRun '%v <command> -h' for help on a command.\n", path)
+}
+
+// newFlagSet creates the flag set for a leaf command. Errors are reported by
+// parseFlags rather than by the flag package exiting the process.
+func newFlagSet(path, short string) *flag.FlagSet {
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %v [flags]\n\n%v\n\nFlags:\n", path, short)
+		fs.SetOutput(os.Stderr)
+		fs.PrintDefaults()
+		fs.SetOutput(io.Discard)
+	}
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return errHelp
+	}
+	if err != nil {
+		return usageErrorf("%v: %v", fs.Name(), err)
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return usageErrorf("%v: unexpected arguments %v", fs.Name(), fs.Args())
+	}
+	return nil
+}
+
+func requireFlag(fs *flag.FlagSet, name, value string) error {
+	if value == "" {
+		fs.Usage()
+		return usageErrorf("%v: -%v is required", fs.Name(), name)
+	}
+	return nil
+}
+
+func exitCode(err error) int {
+	var usageErr usageError
+	switch {
+	case err == nil, errors.Is(err, errHelp):
+		return exitOK
+	case errors.As(err, &usageErr):
+		return exitUsage
+	default:
+		return exitFailure
+	}
+}
+
+// parseStatusCodes parses a comma separated list of gRPC code names such as
+// "OK,UNKNOWN,INVALID_ARGUMENT" or their numeric values.
+func parseStatusCodes(s string) ([]uint32, error) {
+	parsed, err := config.ParseCodes(strings.Split(s, ","))
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("at least one status code is required")
+	}
+
+	result := make([]uint32, 0, len(parsed))
+	for _, c := range parsed {
+		result = append(result, uint32(c))
+	}
+	return result, nil
+}
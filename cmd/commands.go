@@ -0,0 +1,647 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/config"
+	"github.com/VallabhSLEPAM/grpc-client/internal/correlation"
+	"github.com/VallabhSLEPAM/grpc-client/internal/logging"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"github.com/VallabhSLEPAM/grpc-client/internal/tracing"
+)
+
+const programName = "grpc-client"
+
+// execute runs the command line and returns the process exit code.
+func execute(args []string) int {
+	fs := flag.NewFlagSet(programName, flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	flags := newGlobalFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %v [global flags] <command> [flags]\n\nGlobal flags:\n", programName)
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nSettings are read from the defaults, the config file, %v* environment variables\nand these flags, the later source winning.\n\n", config.EnvPrefix)
+		rootCommand().printUsage(os.Stderr, programName)
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	cfg, err := flags.load(os.LookupEnv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v: %v\n", programName, err)
+		return exitUsage
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v: invalid configuration: %v\n", programName, err)
+		return exitUsage
+	}
+	redactor, err := cfg.Redaction.Redactor()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v: invalid configuration: %v\n", programName, err)
+		return exitUsage
+	}
+	redact.SetDefault(redactor)
+
+	logCloser, err := logging.Setup(cfg.Logging.LoggingOptions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v: %v\n", programName, err)
+		return exitFailure
+	}
+	defer logCloser.Close()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.TracingOptions())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v: %v\n", programName, err)
+		return exitFailure
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Failed to flush traces", "error", err)
+		}
+	}()
+
+	app := &app{cfg: cfg}
+	defer app.close()
+	ctx, calls := correlation.WithCapture(context.Background())
+	err = rootCommand().execute(ctx, app, programName, fs.Args())
+	logCapturedMetadata(calls.Metadata())
+	if code := exitCode(err); code != exitOK {
+		fmt.Fprintf(os.Stderr, "%v: %v\n", programName, redact.Default().Error(err))
+		return code
+	}
+	return exitOK
+}
+
+// globalFlags override the configuration for a single run.
+type globalFlags struct {
+	fs            *flag.FlagSet
+	configFile    *string
+	addr          *string
+	caFile        *string
+	certFile      *string
+	keyFile       *string
+	serverName    *string
+	insecureConn  *bool
+	logLevel      *string
+	logFormat     *string
+	logOutput     *string
+	metricsAddr   *string
+	traceExporter *string
+	recordFile    *string
+	replayFile    *string
+}
+
+func newGlobalFlags(fs *flag.FlagSet) *globalFlags {
+	return &globalFlags{
+		fs:            fs,
+		configFile:    fs.String("config", "", "YAML or JSON config file, defaults to $"+config.EnvConfigFile),
+		addr:          fs.String("addr", "", "gRPC server address, overrides server.address"),
+		caFile:        fs.String("ca", "", "CA certificate used to verify the server, overrides tls.caFile"),
+		certFile:      fs.String("cert", "", "client certificate for mutual TLS, overrides tls.certFile"),
+		keyFile:       fs.String("key", "", "client private key for mutual TLS, overrides tls.keyFile"),
+		serverName:    fs.String("server-name", "", "TLS server name override, overrides tls.serverName"),
+		insecureConn:  fs.Bool("insecure", false, "disable transport security, overrides tls.insecure"),
+		logLevel:      fs.String("log-level", "", "debug, info, warn or error, overrides logging.level"),
+		logFormat:     fs.String("log-format", "", "text or json, overrides logging.format"),
+		logOutput:     fs.String("log-output", "", "stderr, stdout or a file path, overrides logging.output"),
+		metricsAddr:   fs.String("metrics-addr", "", "serve Prometheus metrics on this address, overrides metrics.address"),
+		traceExporter: fs.String("trace-exporter", "", "none, stdout or otlp, overrides tracing.exporter"),
+		recordFile:    fs.String("record", "", "record every call to this file with the redaction rules applied, overrides recording"),
+		replayFile:    fs.String("replay", "", "answer every call from this recording instead of the server, overrides recording"),
+	}
+}
+
+// load resolves the configuration and applies the flags that were set on
+// top of it.
+func (g *globalFlags) load(lookupEnv func(string) (string, bool)) (config.Config, error) {
+	cfg, err := config.Load(*g.configFile, lookupEnv)
+	if err != nil {
+		return config.Config{}, err
+	}
+	g.fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "addr":
+			cfg.Server.Address = *g.addr
+		case "ca":
+			cfg.TLS.CAFile = *g.caFile
+		case "cert":
+			cfg.TLS.CertFile = *g.certFile
+		case "key":
+			cfg.TLS.KeyFile = *g.keyFile
+		case "server-name":
+			cfg.TLS.ServerName = *g.serverName
+		case "insecure":
+			cfg.TLS.Insecure = *g.insecureConn
+		case "log-level":
+			cfg.Logging.Level = *g.logLevel
+		case "log-format":
+			cfg.Logging.Format = *g.logFormat
+		case "log-output":
+			cfg.Logging.Output = *g.logOutput
+		case "metrics-addr":
+			cfg.Metrics.Address = *g.metricsAddr
+		case "trace-exporter":
+			cfg.Tracing.Exporter = *g.traceExporter
+		case "record":
+			cfg.Recording.Mode, cfg.Recording.File = config.RecordingModeRecord, *g.recordFile
+		case "replay":
+			cfg.Recording.Mode, cfg.Recording.File = config.RecordingModeReplay, *g.replayFile
+		}
+	})
+	return cfg, nil
+}
+
+func rootCommand() *command {
+	return &command{
+		name:  programName,
+		short: "Client for the hello, bank and resiliency gRPC services.",
+		children: []*command{
+			helloCommand(),
+			bankCommand(),
+			resiliencyCommand(),
+			{name: "chain", short: "Get a balance then call UnaryResiliency within one deadline budget", build: chain},
+			{name: "breakers", short: "Run a command through the circuit breakers and print their state", build: breakers},
+			{name: "loadtest", short: "Run a command under load and report latencies, status codes and breaker trips", build: loadTest},
+		},
+	}
+}
+
+func helloCommand() *command {
+	return &command{
+		name:  "hello",
+		short: "Call the hello service",
+		children: []*command{
+			{name: "say", short: "Unary SayHello", build: helloSay},
+			{name: "server-stream", short: "Server streaming HelloServerStream", build: helloServerStream},
+			{name: "client-stream", short: "Client streaming HelloClientStream", build: helloClientStream},
+			{name: "continuous", short: "Bidirectional streaming HelloContinuous", build: helloContinuous},
+		},
+	}
+}
+
+func helloSay(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Send a single greeting.")
+	name := fs.String("name", "", "name to greet")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "name", *name); err != nil {
+		return nil, err
+	}
+
+	a, err := app.helloAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runSayHello(ctx, *a, *name)
+	}, nil
+}
+
+func helloServerStream(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Receive a stream of greetings for one name.")
+	name := fs.String("name", "", "name to greet")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "name", *name); err != nil {
+		return nil, err
+	}
+
+	a, err := app.helloAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runSayHelloServerStream(ctx, *a, *name)
+	}, nil
+}
+
+func helloClientStream(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Stream several names and receive one greeting.")
+	names := fs.String("names", "", "comma separated names to greet")
+	interval := fs.Duration("interval", 500*time.Millisecond, "pause between two names")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "names", *names); err != nil {
+		return nil, err
+	}
+
+	a, err := app.helloAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runSayHelloClientStream(ctx, *a, splitList(*names), *interval)
+	}, nil
+}
+
+func helloContinuous(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Stream several names and receive a greeting for each.")
+	names := fs.String("names", "", "comma separated names to greet")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "names", *names); err != nil {
+		return nil, err
+	}
+
+	a, err := app.helloAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runSayHelloContinuous(ctx, *a, splitList(*names))
+	}, nil
+}
+
+func bankCommand() *command {
+	return &command{
+		name:  "bank",
+		short: "Call the bank service",
+		children: []*command{
+			{name: "balance", short: "Get the current balance of an account", build: bankBalance},
+			{name: "create-account", short: "Create a new account", build: bankCreateAccount},
+			{name: "rates", short: "Stream exchange rates between two currencies", build: bankRates},
+			{name: "summarize", short: "Summarize dummy transactions for an account", build: bankSummarize},
+			{name: "transfer", short: "Send dummy transfers between two accounts", build: bankTransfer},
+		},
+	}
+}
+
+func bankBalance(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Get the current balance of an account.")
+	acct := fs.String("account", "", "account number")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "account", *acct); err != nil {
+		return nil, err
+	}
+
+	a, err := app.bankAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runGetCurrentBalance(ctx, a, *acct)
+	}, nil
+}
+
+func bankCreateAccount(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Create a new account.")
+	name := fs.String("name", "", "account holder name")
+	currency := fs.String("currency", "USD", "ISO 4217 currency code")
+	deposit := fs.Float64("deposit", 0, "initial deposit amount")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "name", *name); err != nil {
+		return nil, err
+	}
+
+	a, err := app.bankAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runCreateAccount(ctx, a, bank.Account{
+			Name:                 *name,
+			Currency:             *currency,
+			InitialDepositAmount: *deposit,
+		})
+	}, nil
+}
+
+func bankRates(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Stream exchange rates between two currencies.")
+	from := fs.String("from", "", "source currency")
+	to := fs.String("to", "", "target currency")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "from", *from); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "to", *to); err != nil {
+		return nil, err
+	}
+
+	a, err := app.bankAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runFetchExchangeRates(ctx, a, *from, *to)
+	}, nil
+}
+
+func bankSummarize(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Send dummy transactions for an account and print the summary.")
+	acct := fs.String("account", "", "account number")
+	count := fs.Int("count", 5, "number of dummy transactions")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "account", *acct); err != nil {
+		return nil, err
+	}
+
+	a, err := app.bankAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runSummarizeTransactions(ctx, a, *acct, *count)
+	}, nil
+}
+
+func bankTransfer(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Send dummy transfers between two accounts.")
+	from := fs.String("from", "", "source account number")
+	to := fs.String("to", "", "destination account number")
+	currency := fs.String("currency", "USD", "transfer currency")
+	count := fs.Int("count", 10, "number of dummy transfers")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "from", *from); err != nil {
+		return nil, err
+	}
+	if err := requireFlag(fs, "to", *to); err != nil {
+		return nil, err
+	}
+
+	a, err := app.bankAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runTransferMultiple(ctx, a, *from, *to, *currency, *count)
+	}, nil
+}
+
+func resiliencyCommand() *command {
+	return &command{
+		name:  "resiliency",
+		short: "Call the resiliency service",
+		children: []*command{
+			{name: "unary", short: "UnaryResiliency", build: resiliencyUnary},
+			{name: "server", short: "Server streaming ServerResiliency", build: resiliencyServer},
+			{name: "client", short: "Client streaming ClientResiliency", build: resiliencyClient},
+			{name: "bidi", short: "Bidirectional streaming BiDirectionalResiliency", build: resiliencyBidi},
+			{name: "unary-breaker", short: "UnaryResiliency through the circuit breaker", build: resiliencyUnaryBreaker},
+			{name: "unary-metadata", short: "UnaryResiliencyWithMetadata", build: resiliencyUnaryMetadata},
+			{name: "server-metadata", short: "ServerResiliency with request metadata", build: resiliencyServerMetadata},
+			{name: "client-metadata", short: "ClientResiliency with request metadata", build: resiliencyClientMetadata},
+			{name: "bidi-metadata", short: "BiDirectionalResiliency with request metadata", build: resiliencyBidiMetadata},
+		},
+	}
+}
+
+// resiliencyFlags are the request parameters shared by the resiliency commands.
+type resiliencyFlags struct {
+	minDelay int
+	maxDelay int
+	codes    string
+	timeout  time.Duration
+	count    int
+	repeat   int
+	interval time.Duration
+
+	statusCodes []uint32
+}
+
+func newResiliencyFlags(fs *flag.FlagSet) *resiliencyFlags {
+	f := &resiliencyFlags{}
+	fs.IntVar(&f.minDelay, "min-delay", 0, "minimum server delay in seconds")
+	fs.IntVar(&f.maxDelay, "max-delay", 0, "maximum server delay in seconds")
+	fs.StringVar(&f.codes, "codes", "OK", "comma separated status codes the server picks from, e.g. OK,UNKNOWN")
+	return f
+}
+
+func (f *resiliencyFlags) withTimeout(fs *flag.FlagSet) *resiliencyFlags {
+	fs.DurationVar(&f.timeout, "timeout", 0, "deadline for the call, 0 means none")
+	return f
+}
+
+func (f *resiliencyFlags) withCount(fs *flag.FlagSet, def int) *resiliencyFlags {
+	fs.IntVar(&f.count, "count", def, "number of requests sent on the stream")
+	return f
+}
+
+func (f *resiliencyFlags) parse(fs *flag.FlagSet, args []string) error {
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if f.minDelay < 0 || f.maxDelay < f.minDelay {
+		fs.Usage()
+		return usageErrorf("%v: need 0 <= -min-delay <= -max-delay", fs.Name())
+	}
+	codes, err := parseStatusCodes(f.codes)
+	if err != nil {
+		fs.Usage()
+		return usageErrorf("%v: -codes: %v", fs.Name(), err)
+	}
+	f.statusCodes = codes
+	return nil
+}
+
+func resiliencyUnary(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Call UnaryResiliency once.")
+	f := newResiliencyFlags(fs).withTimeout(fs)
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	a, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		if f.timeout > 0 {
+			return runUnaryResiliencyWithTimeout(ctx, a, f.minDelay, f.maxDelay, f.statusCodes, f.timeout)
+		}
+		return runUnaryResiliency(ctx, a, f.minDelay, f.maxDelay, f.statusCodes)
+	}, nil
+}
+
+func resiliencyServer(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Call ServerResiliency and print every streamed response.")
+	f := newResiliencyFlags(fs).withTimeout(fs)
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	a, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		if f.timeout > 0 {
+			return runServerResiliencyWithTimeout(ctx, a, f.minDelay, f.maxDelay, f.statusCodes, f.timeout)
+		}
+		return runServerResiliency(ctx, a, f.minDelay, f.maxDelay, f.statusCodes)
+	}, nil
+}
+
+func resiliencyClient(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Stream requests to ClientResiliency and print the response.")
+	f := newResiliencyFlags(fs).withTimeout(fs).withCount(fs, 3)
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	a, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		if f.timeout > 0 {
+			return runClientResiliencyWithTimeout(ctx, a, f.minDelay, f.maxDelay, f.statusCodes, f.count, f.timeout)
+		}
+		return runClientResiliency(ctx, a, f.minDelay, f.maxDelay, f.statusCodes, f.count)
+	}, nil
+}
+
+func resiliencyBidi(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Stream requests to BiDirectionalResiliency and print every response.")
+	f := newResiliencyFlags(fs).withTimeout(fs).withCount(fs, 4)
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	a, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		if f.timeout > 0 {
+			return runBidirectionalResiliencyWithTimeout(ctx, a, f.minDelay, f.maxDelay, f.statusCodes, f.count, f.timeout)
+		}
+		return runBidirectionalResiliency(ctx, a, f.minDelay, f.maxDelay, f.statusCodes, f.count)
+	}, nil
+}
+
+func resiliencyUnaryBreaker(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Call UnaryResiliency repeatedly through the circuit breaker.")
+	f := newResiliencyFlags(fs)
+	fs.IntVar(&f.repeat, "repeat", 1, "number of calls")
+	fs.DurationVar(&f.interval, "interval", time.Second, "pause between calls")
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	if err := app.enableBreakers(); err != nil {
+		return nil, err
+	}
+	a, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		var failures int
+		for i := 0; i < f.repeat; i++ {
+			if i > 0 {
+				time.Sleep(f.interval)
+			}
+			if err := runUnaryResiliency(ctx, a, f.minDelay, f.maxDelay, f.statusCodes); err != nil {
+				failures++
+				fmt.Fprintf(os.Stderr, "call %v: %v\n", i+1, redact.Default().Error(err))
+			}
+		}
+		if failures > 0 {
+			return fmt.Errorf("%v of %v calls failed", failures, f.repeat)
+		}
+		return nil
+	}, nil
+}
+
+func resiliencyUnaryMetadata(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Call UnaryResiliencyWithMetadata and print the response metadata.")
+	f := newResiliencyFlags(fs)
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	a, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runUnaryResiliencyWithMetadata(ctx, a, f.minDelay, f.maxDelay, f.statusCodes)
+	}, nil
+}
+
+func resiliencyServerMetadata(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Call ServerResiliency with request metadata.")
+	f := newResiliencyFlags(fs)
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	a, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runServerResiliencyWithMetadata(ctx, a, f.minDelay, f.maxDelay, f.statusCodes)
+	}, nil
+}
+
+func resiliencyClientMetadata(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Call ClientResiliency with request metadata.")
+	f := newResiliencyFlags(fs).withCount(fs, 3)
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	a, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runClientResiliencyWithMetadata(ctx, a, f.minDelay, f.maxDelay, f.statusCodes, f.count)
+	}, nil
+}
+
+func resiliencyBidiMetadata(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path, "Call BiDirectionalResiliency with request metadata.")
+	f := newResiliencyFlags(fs).withCount(fs, 4)
+	if err := f.parse(fs, args); err != nil {
+		return nil, err
+	}
+
+	a, err := app.resiliencyAdapter()
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context) error {
+		return runBidirectionalResiliencyWithMetadata(ctx, a, f.minDelay, f.maxDelay, f.statusCodes, f.count)
+	}, nil
+}
+
+func splitList(s string) []string {
+	var result []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			result = append(result, part)
+		}
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/config"
+	"github.com/VallabhSLEPAM/grpc-client/internal/recording"
+)
+
+func TestGlobalFlagsOverrideConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.yaml")
+	content := "server:\n  address: file:9090\nlogging:\n  level: warn\n  format: json\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	lookupEnv := func(name string) (string, bool) {
+		switch name {
+		case config.EnvPrefix + "SERVER_ADDRESS":
+			return "env:9090", true
+		case config.EnvPrefix + "LOG_LEVEL":
+			return "error", true
+		}
+		return "", false
+	}
+
+	fs := flag.NewFlagSet(programName, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	flags := newGlobalFlags(fs)
+	if err := fs.Parse([]string{"-config", path, "-addr", "flag:9090", "-insecure", "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := flags.load(lookupEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Address != "flag:9090" {
+		t.Errorf("Server.Address = %q, want the flag value", cfg.Server.Address)
+	}
+	if !cfg.TLS.Insecure {
+		t.Error("TLS.Insecure = false, want the flag value")
+	}
+	if cfg.Logging.Level != "error" {
+		t.Errorf("Logging.Level = %q, want the environment value", cfg.Logging.Level)
+	}
+	if cfg.Logging.Format != "json" {
+		t.Errorf("Logging.Format = %q, want the file value", cfg.Logging.Format)
+	}
+	if cfg.TLS.CAFile != config.Default().TLS.CAFile {
+		t.Errorf("TLS.CAFile = %q, want the default", cfg.TLS.CAFile)
+	}
+	if got := fs.Args(); len(got) != 1 || got[0] != "hello" {
+		t.Errorf("Args() = %q, want the command left over", got)
+	}
+}
+
+func TestLoadTestResolvesWithoutCalling(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.json")
+	rec, err := recording.Create(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := rec.Begin("/hello.HelloService/SayHello", recording.KindUnary, nil)
+	if err := e.AddResponse(&hello.HelloResponse{Greet: "Hello a"}); err != nil {
+		t.Fatal(err)
+	}
+	e.Finish(nil, nil, nil)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.TLS.Insecure = true
+	cfg.Recording.Mode, cfg.Recording.File = config.RecordingModeReplay, path
+	app := &app{cfg: cfg}
+	defer app.close()
+
+	if _, err := rootCommand().resolve(app, programName, []string{"loadtest", "hello", "say"}); exitCode(err) != exitUsage {
+		t.Errorf("resolve() without -name = %v, want a usage error", err)
+	}
+	if _, err := rootCommand().resolve(app, programName, []string{"loadtest", "-duration", "1s", "hello", "say", "-name", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := app.replayer.Remaining(); n != 1 {
+		t.Errorf("%d recorded calls left after resolve, want the call not made yet", n)
+	}
+}
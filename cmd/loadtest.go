@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/loadtest"
+	"github.com/VallabhSLEPAM/grpc-client/internal/logging"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+)
+
+func loadTest(app *app, path string, args []string) (call, error) {
+	fs := newFlagSet(path,
+		"Run a command over and over, at a target rate or with a number of concurrent workers,\nthen print the latency percentiles, status codes and circuit breaker trips, e.g.\n\n  "+path+" -rate 50 -duration 1m -ramp-up 10s resiliency unary -codes OK,UNAVAILABLE\n\nThe command is parsed once, every call of the run is counted.")
+	rate := fs.Float64("rate", 0, "calls started per second, 0 makes -concurrency workers call back to back")
+	concurrency := fs.Int("concurrency", 10, "number of workers, or with -rate the most calls in flight")
+	duration := fs.Duration("duration", 10*time.Second, "how long to run")
+	rampUp := fs.Duration("ramp-up", 0, "raise the load linearly over this part of the duration")
+	withBreakers := fs.Bool("breakers", true, "run the calls through the circuit breakers")
+	jsonOutput := fs.Bool("json", false, "print the report as JSON")
+	quiet := fs.Bool("quiet", true, "drop the logs of the calls, errors are counted in the report")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, errHelp
+		}
+		return nil, usageErrorf("%v: %v", path, err)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return nil, usageErrorf("%v: missing command", path)
+	}
+	opts := loadtest.Options{
+		Rate:        *rate,
+		Concurrency: *concurrency,
+		Duration:    *duration,
+		RampUp:      *rampUp,
+	}
+	if err := opts.Validate(); err != nil {
+		fs.Usage()
+		return nil, usageErrorf("%v: %v", path, err)
+	}
+
+	if *withBreakers {
+		if err := app.enableBreakers(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := app.clientConn(); err != nil {
+		return nil, err
+	}
+	opts.Breakers = app.breakers
+
+	run, err := rootCommand().resolve(app, programName, fs.Args())
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		if *quiet {
+			prev := slog.Default()
+			// Errors are counted in the report instead.
+			slog.SetDefault(slog.New(logging.MinLevel(prev.Handler(), slog.LevelError+1)))
+			defer slog.SetDefault(prev)
+		}
+
+		runCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		report, err := loadtest.Run(runCtx, opts, loadtest.Call(run))
+		if err != nil {
+			return err
+		}
+
+		for i, msg := range report.ErrorSamples {
+			report.ErrorSamples[i] = redact.Default().String(msg)
+		}
+		if *jsonOutput {
+			return report.WriteJSON(os.Stdout)
+		}
+		return report.WriteText(os.Stdout)
+	}, nil
+}
@@ -2,163 +2,310 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"math/rand"
+	"net/http"
+	"os"
 	"time"
 
-	protogenResiliency "github.com/VallabhSLEPAM/go-with-grpc/protogen/go/resiliency"
 	bankadapter "github.com/VallabhSLEPAM/grpc-client/internal/adapter/bank"
 	adapter "github.com/VallabhSLEPAM/grpc-client/internal/adapter/hello"
 	"github.com/VallabhSLEPAM/grpc-client/internal/adapter/resiliency"
 	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/bank"
+	resiliencydomain "github.com/VallabhSLEPAM/grpc-client/internal/application/domain/resiliency"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
+	"github.com/VallabhSLEPAM/grpc-client/internal/config"
+	"github.com/VallabhSLEPAM/grpc-client/internal/correlation"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/recording"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"github.com/VallabhSLEPAM/grpc-client/internal/tlsconfig"
 
-	"github.com/sony/gobreaker"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
-var circuitBreaker *gobreaker.CircuitBreaker
+// version is reported to the server, set it with
+// -ldflags "-X main.version=...".
+var version = "dev"
 
-func init() {
-	myBreaker := gobreaker.Settings{
-		Name: "my-circuit-breaker",
-		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			failureRatios := float64(counts.TotalFailures) / float64(counts.Requests)
+func main() {
+	os.Exit(execute(os.Args[1:]))
+}
 
-			log.Printf("Circuit Breaker %v, Total requests %v. Failure ratio: %v\n", counts.TotalFailures, counts.Requests, failureRatios)
-			return failureRatios > 0.6 && counts.Requests >= 3
-		},
-		Timeout:     4 * time.Second,
-		MaxRequests: 3,
-		OnStateChange: func(name string, from, to gobreaker.State) {
-			log.Printf("Circuit Breaker %v changed state from %v to %v\n", name, from, to)
-		},
-	}
+// app holds the resolved configuration and the lazily created client
+// connection shared by all subcommands.
+type app struct {
+	cfg config.Config
+
+	conn *grpc.ClientConn
+
+	// breakers is set while the circuit breaker interceptors are enabled.
+	breakers *interceptor.BreakerRegistry
+
+	// metrics is set while the /metrics endpoint is enabled.
+	metrics       *interceptor.Metrics
+	metricsServer *http.Server
 
-	circuitBreaker = gobreaker.NewCircuitBreaker(myBreaker)
+	// Depending on the recording mode one of them is set.
+	recorder *recording.Recorder
+	replayer *recording.Replayer
+
+	// stopReload stops the certificate reloader, if one was started.
+	stopReload context.CancelFunc
 }
 
-func main() {
+func (a *app) clientConn() (*grpc.ClientConn, error) {
+	if a.conn != nil {
+		return a.conn, nil
+	}
 
-	var opts []grpc.DialOption
-	creds, err := credentials.NewClientTLSFromFile("ssl/ca.crt", "")
+	creds, err := a.transportCredentials()
 	if err != nil {
-		log.Fatalln("Error creating client credentials: ", err)
+		return nil, err
 	}
 
-	opts = append(opts, grpc.WithTransportCredentials(creds))
-	// opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	// opts = append(opts,
-	// 	grpc.WithUnaryInterceptor(
-	// 		grpc_retry.UnaryClientInterceptor(
-	// 			grpc_retry.WithCodes(codes.Unknown, codes.Internal),
-	// 			grpc_retry.WithMax(4),
-	// 			grpc_retry.WithBackoff(grpc_retry.BackoffExponential(2*time.Second)),
-	// 		),
-	// 	),
-	// )
-
-	// opts = append(opts,
-	// 	grpc.WithStreamInterceptor(
-	// 		grpc_retry.StreamClientInterceptor(
-	// 			grpc_retry.WithCodes(codes.Unknown, codes.Internal),
-	// 			grpc_retry.WithMax(4),
-	// 			grpc_retry.WithBackoff(grpc_retry.BackoffLinear(3*time.Second)),
-	// 		),
-	// 	),
-	// )
-
-	// opts = append(opts,
-	// 	grpc.WithChainUnaryInterceptor(
-	// 		interceptor.LogUnaryClientInterceptor(),
-	// 		interceptor.BasicUnaryClientInterceptor(),
-	// 		interceptor.UnaryTimeoutInterceptor(5*time.Second),
-	// 	))
-
-	// opts = append(opts,
-	// 	grpc.WithChainStreamInterceptor(
-	// 		interceptor.BasicClientStreamInterceptor(),
-	// 		interceptor.LogStreamClientInterceptor(),
-	// 		interceptor.TimeoutStreamClientIntereptor(5*time.Second),
-	// 	),
-	// )
-
-	// Create a gRPC client with TLS credentials
-	grpcClient, err := grpc.NewClient("localhost:9090", opts...)
-	if err != nil {
-		log.Fatalf("Cannot connect to gRPC server :%v", err)
+	if a.cfg.Breaker.Enabled {
+		a.breakers = interceptor.NewBreakerRegistry(a.cfg.Breaker.BreakerOptions())
 	}
 
-	//defer to close the gRPC client connection
-	defer grpcClient.Close()
+	if a.cfg.Metrics.Address != "" {
+		if err := a.startMetrics(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch a.cfg.Recording.Mode {
+	case config.RecordingModeRecord:
+		if a.recorder, err = recording.Create(a.cfg.Recording.File, redact.Default()); err != nil {
+			return nil, err
+		}
+	case config.RecordingModeReplay:
+		if a.replayer, err = recording.Load(a.cfg.Recording.File); err != nil {
+			return nil, err
+		}
+	}
 
-	// Adapter is just a wrapper to create Service client from protogen file passing it the grpc client created earlier
-	helloAdapter, err := adapter.NewHelloAdapter(grpcClient)
+	opts := a.dialOptions(creds)
+
+	conn, err := grpc.NewClient(a.cfg.Server.Address, opts...)
 	if err != nil {
-		log.Fatalf("Error while creating HelloAdapter :%v", err)
+		return nil, fmt.Errorf("cannot connect to gRPC server %v: %w", a.cfg.Server.Address, err)
 	}
+	a.conn = conn
+	return conn, nil
+}
 
-	runSayHello(*helloAdapter, "Bruce Banner")
+// enableBreakers turns the circuit breakers on. They are set up when dialing,
+// so this fails once the connection was dialed without them, as by loadtest
+// -breakers=false.
+func (a *app) enableBreakers() error {
+	if a.conn != nil && a.breakers == nil {
+		return usageErrorf("the circuit breakers are off for this connection, they cannot be used with loadtest -breakers=false")
+	}
+	a.cfg.Breaker.Enabled = true
+	return nil
+}
 
-	helloAdapter.SayHelloServerStream(context.Background(), "Scarlet Witch")
+func (a *app) transportCredentials() (credentials.TransportCredentials, error) {
+	// Replayed calls never reach the server, there is nothing to secure and
+	// the certificates need not be around.
+	if a.cfg.TLS.Insecure || a.cfg.Recording.Mode == config.RecordingModeReplay {
+		return insecure.NewCredentials(), nil
+	}
 
-	// helloAdapter.SayHelloClientStream(context.Background(), []string{"Vallabh", "Ashish", "Steve", "Somnath"})
+	if a.cfg.TLS.ReloadInterval <= 0 {
+		creds, err := tlsconfig.NewClientCredentials(a.cfg.TLS.TLSOptions())
+		if err != nil {
+			return nil, fmt.Errorf("creating client credentials: %w", err)
+		}
+		return creds, nil
+	}
 
-	// Call the gRPC server method from adapter wrapper by making use of the service client created earlier
-	// helloAdapter.SayHelloContinuous(context.Background(), []string{"Superman", "Joker", "Batman", "Aquaman", "Flash"})
+	reloader, err := tlsconfig.NewReloader(a.cfg.TLS.TLSOptions(), func(event tlsconfig.ReloadEvent) {
+		if event.Err != nil {
+			slog.Error("Failed to reload TLS certificates, keeping the previous ones", "error", event.Err)
+			return
+		}
+		slog.Info("Reloaded TLS certificates")
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating client credentials: %w", err)
+	}
 
-	// bAdapter, err := bankadapter.NewBankAdapter(grpcClient)
-	// if err != nil {
-	// 	log.Fatalf("Error while creating BankAdapter :%v", err)
-	// }
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopReload = cancel
+	go reloader.Run(ctx, a.cfg.TLS.ReloadInterval.Std())
+	return reloader.Credentials(), nil
+}
 
-	// runGetCurrentBalance(bAdapter, "7835697001xx")
-	// runFetchExchangeRates(bAdapter, "USD", "INR")
-	//runSummarizeTransactions(bAdapter, "7835697002", 5)
-	// runTransferMultiple(bAdapter, "7835697001", "7835697002", 10)
+func (a *app) dialOptions(creds credentials.TransportCredentials) []grpc.DialOption {
+	cfg := a.cfg
+	var opts []grpc.DialOption
 
-	// resiliencyAdapter, err := resiliency.NewResiliencyAdapter(grpcClient)
-	// if err != nil {
-	// 	log.Fatalf("Error while creating ResiliencyAdapter :%v", err)
-	// }
-	// runUnaryResiliency(resiliencyAdapter, 0, 5, []uint32{applicationResiliency.OK})
-	// runUnaryResiliencyWithTimeout(resiliencyAdapter, 0, 5, []uint32{applicationResiliency.OK}, 5*time.Second)
+	opts = append(opts, grpc.WithTransportCredentials(creds))
 
-	//runServerResiliencyWithTimeout(resiliencyAdapter, 2, 6, []uint32{applicationResiliency.OK}, 3*time.Second)
-	//runClientResiliencyWithTimeout(resiliencyAdapter, 2, 8, []uint32{applicationResiliency.OK}, 60*time.Second)
+	var unary []grpc.UnaryClientInterceptor
+	var stream []grpc.StreamClientInterceptor
+
+	// Without an exporter the spans are not recorded, but the trace context
+	// of ctx is still injected.
+	unary = append(unary, interceptor.TracingUnaryClientInterceptor(interceptor.TracingOptions{}))
+	stream = append(stream, interceptor.TracingStreamClientInterceptor(interceptor.TracingOptions{}))
+	// Outside of the retries so every attempt shares the correlation ID.
+	if cfg.Interceptors.Correlation {
+		correlationOpts := interceptor.CorrelationOptions{Version: version}
+		unary = append(unary, interceptor.CorrelationUnaryClientInterceptor(correlationOpts))
+		stream = append(stream, interceptor.CorrelationStreamClientInterceptor(correlationOpts))
+	}
+	if a.metrics != nil {
+		unary = append(unary, interceptor.MetricsUnaryClientInterceptor(a.metrics))
+		stream = append(stream, interceptor.MetricsStreamClientInterceptor(a.metrics))
+	}
+	if cfg.Interceptors.Logging {
+		unary = append(unary, interceptor.LoggingUnaryClientInterceptor(interceptor.LoggingOptions{}))
+		stream = append(stream, interceptor.LoggingStreamClientInterceptor(interceptor.LoggingOptions{}))
+	}
+	if cfg.Interceptors.Metadata {
+		unary = append(unary, interceptor.BasicUnaryClientInterceptor())
+		stream = append(stream, interceptor.BasicClientStreamInterceptor())
+	}
+	if cfg.Timeouts.Enabled() {
+		unary = append(unary, interceptor.TimeoutUnaryClientInterceptor(cfg.Timeouts.UnaryOptions()))
+		stream = append(stream, interceptor.TimeoutStreamClientInterceptor(cfg.Timeouts.StreamOptions()))
+	}
+	// A call rejected by an open breaker is not retried, and retries of a
+	// call count once against its breaker.
+	if a.breakers != nil {
+		unary = append(unary, interceptor.BreakerUnaryClientInterceptor(a.breakers))
+		stream = append(stream, interceptor.BreakerStreamClientInterceptor(a.breakers))
+	}
+	// Retries sit inside the timeouts so the overall deadline bounds them.
+	if cfg.Retry.Enabled {
+		retryOpts := cfg.Retry.RetryOptions()
+		unary = append(unary, interceptor.RetryUnaryClientInterceptor(retryOpts))
+		stream = append(stream, interceptor.RetryStreamClientInterceptor(retryOpts))
+	}
+	// Inside the retries and breakers so they see the injected faults.
+	if cfg.Faults.Enabled {
+		faultOpts := cfg.Faults.FaultOptions()
+		unary = append(unary, interceptor.FaultUnaryClientInterceptor(faultOpts))
+		stream = append(stream, interceptor.FaultStreamClientInterceptor(faultOpts))
+	}
+	// Innermost, so every attempt is recorded or replayed on its own.
+	if a.recorder != nil {
+		unary = append(unary, interceptor.RecordUnaryClientInterceptor(a.recorder))
+		stream = append(stream, interceptor.RecordStreamClientInterceptor(a.recorder))
+	}
+	if a.replayer != nil {
+		replayOpts := interceptor.ReplayOptions{Replayer: a.replayer, Strict: cfg.Recording.Strict, Redactor: redact.Default()}
+		unary = append(unary, interceptor.ReplayUnaryClientInterceptor(replayOpts))
+		stream = append(stream, interceptor.ReplayStreamClientInterceptor(replayOpts))
+	}
 
-	// runUnaryResiliencyWithMetadata(resiliencyAdapter, 0, 5, []uint32{applicationResiliency.OK})
-	//runServerResiliencyWithMetadata(resiliencyAdapter, 0, 5, []uint32{applicationResiliency.OK})
+	opts = append(opts,
+		grpc.WithChainUnaryInterceptor(unary...),
+		grpc.WithChainStreamInterceptor(stream...),
+	)
+	return opts
+}
 
-	// for i := 0; i < 300; i++ {
-	// 	runUnaryResiliencyWithCircuitBreaker(resiliencyAdapter, 0, 3, []uint32{applicationResiliency.OK, applicationResiliency.UNKNOWN})
-	// 	time.Sleep(time.Second)
-	// }
+func (a *app) close() {
+	if a.conn != nil {
+		a.conn.Close()
+	}
+	a.stopMetrics()
+	if a.recorder != nil {
+		if err := a.recorder.Close(); err != nil {
+			slog.Error("Failed to write the recording", "error", err)
+		}
+	}
+	if a.stopReload != nil {
+		a.stopReload()
+	}
+}
+
+func (a *app) helloAdapter() (*adapter.HelloAdapter, error) {
+	conn, err := a.clientConn()
+	if err != nil {
+		return nil, err
+	}
+	return adapter.NewHelloAdapter(conn)
+}
+
+func (a *app) bankAdapter() (bankadapter.BankAdapter, error) {
+	conn, err := a.clientConn()
+	if err != nil {
+		return bankadapter.BankAdapter{}, err
+	}
+	return bankadapter.NewBankAdapter(conn)
+}
 
+func (a *app) resiliencyAdapter() (*resiliency.ResiliencyAdapter, error) {
+	conn, err := a.clientConn()
+	if err != nil {
+		return nil, err
+	}
+	return resiliency.NewResiliencyAdapter(conn)
 }
 
-func runSayHello(adapter adapter.HelloAdapter, name string) {
-	greet, err := adapter.SayHello(context.Background(), name)
+func runSayHello(ctx context.Context, adapter adapter.HelloAdapter, name string) error {
+	greet, err := adapter.SayHello(ctx, name)
 	if err != nil {
-		log.Fatalf("Error while creating HelloAdapter :%v", err)
+		return err
 	}
 	log.Println(greet.Greet)
+	return nil
 }
 
-func runGetCurrentBalance(adapter bankadapter.BankAdapter, acct string) {
-	bal, err := adapter.GetCurrentBalance(context.Background(), acct)
+func runSayHelloServerStream(ctx context.Context, adapter adapter.HelloAdapter, name string) error {
+	return adapter.SayHelloServerStream(ctx, name)
+}
+
+func runSayHelloClientStream(ctx context.Context, adapter adapter.HelloAdapter, names []string, interval time.Duration) error {
+	return adapter.SayHelloClientStream(ctx, names, interval)
+}
+
+func runSayHelloContinuous(ctx context.Context, adapter adapter.HelloAdapter, names []string) error {
+	return adapter.SayHelloContinuous(ctx, names)
+}
+
+func runGetCurrentBalance(ctx context.Context, adapter bankadapter.BankAdapter, acct string) error {
+	bal, err := adapter.GetCurrentBalance(ctx, acct)
+	if errors.Is(err, rpcerror.ErrAccountNotFound) {
+		return fmt.Errorf("account %v does not exist: %w", acct, err)
+	}
 	if err != nil {
-		log.Fatalln("Failed to call GetCurrentBalance: ", err)
+		return err
 	}
 	log.Println("Current balance: ", bal)
+	return nil
 }
 
-func runFetchExchangeRates(adapter bankadapter.BankAdapter, fromAcct, toAcct string) {
-	adapter.FetchExchangeRates(context.Background(), fromAcct, toAcct)
+func runCreateAccount(ctx context.Context, adapter bankadapter.BankAdapter, acct bank.Account) error {
+	created, err := adapter.CreateAccount(ctx, acct)
+	if err != nil {
+		return err
+	}
+	log.Println("Created account: ", created.UUID)
+	return nil
+}
+
+func runFetchExchangeRates(ctx context.Context, adapter bankadapter.BankAdapter, fromCurr, toCurr string) error {
+	for rate, err := range adapter.FetchExchangeRates(ctx, fromCurr, toCurr) {
+		if err != nil {
+			return err
+		}
+		log.Printf("Rates at %v from %v to %v: %v\n", rate.RawTimestamp, rate.From, rate.To, rate.Rate)
+	}
+	return nil
 }
 
-func runSummarizeTransactions(adapter bankadapter.BankAdapter, acct string, numDummyTransactions int) {
+func runSummarizeTransactions(ctx context.Context, adapter bankadapter.BankAdapter, acct string, numDummyTransactions int) error {
 
 	var txs []bank.Transaction
 	for i := 1; i <= numDummyTransactions; i++ {
@@ -171,16 +318,21 @@ func runSummarizeTransactions(adapter bankadapter.BankAdapter, acct string, numD
 		t := bank.Transaction{
 			Amount:          float64(rand.Intn(500) + 10),
 			TransactionType: ttype,
-			Notes:           fmt.Sprint("Dummy transaction:%v", i),
+			Notes:           fmt.Sprintf("Dummy transaction:%v", i),
 		}
 
 		txs = append(txs, t)
 	}
 
-	adapter.SummarizeTransactions(context.Background(), acct, txs)
+	summary, err := adapter.SummarizeTransactions(ctx, acct, txs)
+	if err != nil {
+		return err
+	}
+	log.Printf("Summary for %v on %v: in %v, out %v, total %v\n", summary.AccountNumber, summary.TransactionDate.Format(time.DateOnly), summary.SumAmountIn, summary.SumAmountOut, summary.SumTotal)
+	return nil
 }
 
-func runTransferMultiple(adapter bankadapter.BankAdapter, fromAcct, toAcct string, numDummyTransactions int) {
+func runTransferMultiple(ctx context.Context, adapter bankadapter.BankAdapter, fromAcct, toAcct, currency string, numDummyTransactions int) error {
 
 	var trf []bank.TransferTransaction
 
@@ -188,110 +340,134 @@ func runTransferMultiple(adapter bankadapter.BankAdapter, fromAcct, toAcct strin
 		tr := bank.TransferTransaction{
 			FromAccountNumber: fromAcct,
 			ToAccountNumber:   toAcct,
-			Currency:          "USD",
+			Currency:          currency,
 			Amount:            float64(rand.Intn(200) + 5),
 		}
 
 		trf = append(trf, tr)
 	}
 
-	adapter.TransferMultiple(context.Background(), trf)
-
+	res, err := adapter.TransferMultiple(ctx, trf)
+	if err != nil {
+		for _, f := range res.Failures {
+			log.Printf("Transfer failure on %v: %v %v\n", redact.Default().String(f.Subject), f.Reason, redact.Default().String(f.Description))
+		}
+		return err
+	}
+	log.Printf("Transfer status %v on %v", res.Status, res.Timestamp)
+	return nil
 }
 
-func runUnaryResiliencyWithTimeout(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, timeout time.Duration) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+func runUnaryResiliencyWithTimeout(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 
 	defer cancel()
 	resp, err := adapter.UnaryResiliency(ctx, minDelay, maxDelay, statusCodes)
 	if err != nil {
-		log.Fatalln("Failed to call UnaryResiliency", err)
+		return err
 	}
 	log.Println(resp.DummyString)
+	return nil
 }
 
-func runServerResiliencyWithTimeout(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, timeout time.Duration) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+func runServerResiliencyWithTimeout(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 
 	defer cancel()
-	adapter.ServerResiliency(ctx, minDelay, maxDelay, statusCodes)
+	return adapter.ServerResiliency(ctx, minDelay, maxDelay, statusCodes)
 }
 
-func runClientResiliencyWithTimeout(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, timeout time.Duration) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+func runClientResiliencyWithTimeout(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, count int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 
 	defer cancel()
-	adapter.ClientResiliency(ctx, minDelay, maxDelay, statusCodes, 3)
+	return adapter.ClientResiliency(ctx, minDelay, maxDelay, statusCodes, count)
 }
 
-func runBidirectionalResiliencyWithTimeout(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, timeout time.Duration) {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+func runBidirectionalResiliencyWithTimeout(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, count int, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 
 	defer cancel()
-	adapter.BiDirectionalResiliency(ctx, minDelay, maxDelay, statusCodes, 4)
+	return adapter.BiDirectionalResiliency(ctx, minDelay, maxDelay, statusCodes, count)
 }
 
 // Without timeout
-func runUnaryResiliency(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) {
+func runUnaryResiliency(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) error {
 
-	resp, err := adapter.UnaryResiliency(context.Background(), minDelay, maxDelay, statusCodes)
+	resp, err := adapter.UnaryResiliency(ctx, minDelay, maxDelay, statusCodes)
 	if err != nil {
-		log.Fatalln("Failed to call UnaryResiliency", err)
+		return err
 	}
 	log.Println(resp.DummyString)
+	return nil
 }
 
-func runServerResiliency(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) {
+func runServerResiliency(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) error {
 
-	adapter.ServerResiliency(context.Background(), minDelay, maxDelay, statusCodes)
+	return adapter.ServerResiliency(ctx, minDelay, maxDelay, statusCodes)
 }
 
-func runClientResiliency(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) {
+func runClientResiliency(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, count int) error {
 
-	adapter.ClientResiliency(context.Background(), minDelay, maxDelay, statusCodes, 3)
+	return adapter.ClientResiliency(ctx, minDelay, maxDelay, statusCodes, count)
 }
 
-func runBidirectionalResiliency(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) {
+func runBidirectionalResiliency(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, count int) error {
 
-	adapter.BiDirectionalResiliency(context.Background(), minDelay, maxDelay, statusCodes, 4)
+	return adapter.BiDirectionalResiliency(ctx, minDelay, maxDelay, statusCodes, count)
 }
 
-// WithCircuit Breaker
-func runUnaryResiliencyWithCircuitBreaker(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) {
-
-	cBreakerRes, cBreakerErr := circuitBreaker.Execute(func() (interface{}, error) {
-		return adapter.UnaryResiliency(context.Background(), minDelay, maxDelay, statusCodes)
-	})
-
-	if cBreakerErr != nil {
-		log.Fatalln("Failed to call UnaryResiliency: ", cBreakerErr)
-	} else {
-		log.Println(cBreakerRes.(*protogenResiliency.ResiliencyResponse).DummyString)
+// Without timeout
+func runUnaryResiliencyWithMetadata(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) error {
 
+	resp, md, err := adapter.UnaryResiliencyWithMetadata(ctx, minDelay, maxDelay, statusCodes)
+	logCallMetadata(md)
+	if err != nil {
+		return err
 	}
+	log.Println(resp.DummyString)
+	return nil
 }
 
-// Without timeout
-// func runUnaryResiliencyWithMetadata(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) {
+func runServerResiliencyWithMetadata(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) error {
 
-// 	resp, err := adapter.UnaryResiliencyWithMetadata(context.Background(), minDelay, maxDelay, statusCodes)
-// 	if err != nil {
-// 		log.Fatalln("Failed to call UnaryResiliency", err)
-// 	}
-// 	log.Println(resp.DummyString)
-// }
+	md, err := adapter.ServerResiliencyWithMetadata(ctx, minDelay, maxDelay, statusCodes)
+	logCallMetadata(md)
+	return err
+}
 
-func runServerResiliencyWithMetadata(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) {
+func runClientResiliencyWithMetadata(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, count int) error {
 
-	adapter.ServerResiliencyWithMetadata(context.Background(), minDelay, maxDelay, statusCodes)
+	md, err := adapter.ClientResiliencyWithMetadata(ctx, minDelay, maxDelay, statusCodes, count)
+	logCallMetadata(md)
+	return err
 }
 
-// func runClientResiliencyWithMetadata(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) {
+func runBidirectionalResiliencyWithMetadata(ctx context.Context, adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32, count int) error {
 
-// 	adapter.ClientResiliencyWithMetadata(context.Background(), minDelay, maxDelay, statusCodes, 3)
-// }
+	md, err := adapter.BiDirectionalResiliencyWithMetadata(ctx, minDelay, maxDelay, statusCodes, count)
+	logCallMetadata(md)
+	return err
+}
 
-// func runBidirectionalResiliencyWithMetadata(adapter *resiliency.ResiliencyAdapter, minDelay, maxDelay int, statusCodes []uint32) {
+// logCapturedMetadata logs the response metadata of the last call made by a
+// command, as captured by the correlation interceptors, at debug level.
+func logCapturedMetadata(md correlation.Metadata) {
+	if md.ID == "" {
+		return
+	}
+	slog.Debug("Response metadata of the last call", "correlation_id", md.ID, "header", md.Header, "trailer", md.Trailer)
+}
 
-// 	adapter.BiDirectionalResiliencyWithMetadata(context.Background(), minDelay, maxDelay, statusCodes, 4)
-// }
+func logCallMetadata(md resiliencydomain.CallMetadata) {
+	slog.Info("Call metadata", "correlation_id", md.CorrelationID)
+	if len(md.Header) == 0 && len(md.Trailer) == 0 {
+		slog.Info("No response metadata found")
+	}
+	for k, v := range md.Header {
+		slog.Info("Response header", "key", k, "value", v)
+	}
+	for k, v := range md.Trailer {
+		slog.Info("Response trailer", "key", k, "value", v)
+	}
+}
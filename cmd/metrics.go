@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// startMetrics serves /metrics on the configured address until close.
+func (a *app) startMetrics() error {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	metrics, err := interceptor.NewMetrics(reg)
+	if err != nil {
+		return err
+	}
+	if a.breakers != nil {
+		reg.MustRegister(interceptor.NewBreakerCollector(a.breakers))
+	}
+
+	lis, err := net.Listen("tcp", a.cfg.Metrics.Address)
+	if err != nil {
+		return fmt.Errorf("serving metrics: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(lis); !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server stopped", "error", err)
+		}
+	}()
+	slog.Info("Serving metrics", "address", "http://"+lis.Addr().String()+"/metrics")
+
+	a.metrics = metrics
+	a.metricsServer = server
+	return nil
+}
+
+// stopMetrics keeps serving for metrics.linger so a last scrape can pick up
+// the final values, then shuts the server down.
+func (a *app) stopMetrics() {
+	if a.metricsServer == nil {
+		return
+	}
+	if linger := a.cfg.Metrics.Linger.Std(); linger > 0 {
+		slog.Info("Keeping the metrics endpoint up", "linger", linger)
+		time.Sleep(linger)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	a.metricsServer.Shutdown(ctx)
+}
@@ -2,16 +2,18 @@ package bank
 
 import (
 	"context"
+	"errors"
 	"io"
-	"log"
+	"iter"
+	"log/slog"
 
 	protogenbank "github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
 	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
 	"github.com/VallabhSLEPAM/grpc-client/internal/port"
-	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"github.com/VallabhSLEPAM/grpc-client/internal/tracing"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 type BankAdapter struct {
@@ -20,12 +22,20 @@ type BankAdapter struct {
 
 func NewBankAdapter(conn *grpc.ClientConn) (BankAdapter, error) {
 	client := protogenbank.NewBankServiceClient(conn)
+	return NewBankAdapterFromClient(client), nil
+}
+
+// NewBankAdapterFromClient builds the adapter on any implementation of the
+// port, such as the mocks of package port/mock.
+func NewBankAdapterFromClient(client port.BankClientPort) BankAdapter {
 	return BankAdapter{
 		bankClient: client,
-	}, nil
+	}
 }
 
 func (adapter BankAdapter) GetCurrentBalance(ctx context.Context, acctNumber string) (*protogenbank.CurrentBalanceResponse, error) {
+	ctx, span := tracing.Start(ctx, "BankAdapter.GetCurrentBalance")
+	defer span.End()
 
 	bankRequest := protogenbank.CurrentBalanceRequest{
 		AccountNumber: acctNumber,
@@ -33,47 +43,80 @@ func (adapter BankAdapter) GetCurrentBalance(ctx context.Context, acctNumber str
 
 	bal, err := adapter.bankClient.GetCurrentBalance(ctx, &bankRequest)
 	if err != nil {
-		st, _ := status.FromError(err)
-		log.Fatalf("[FATAL] Error on GetCurrentBalance: ", st)
+		return nil, tracing.Error(span, rpcerror.New("GetCurrentBalance", err))
 	}
 	return bal, nil
 }
 
-func (adapter BankAdapter) FetchExchangeRates(ctx context.Context, fromCurr, toCurr string) {
+func (adapter BankAdapter) CreateAccount(ctx context.Context, acct bank.Account) (bank.Account, error) {
+	ctx, span := tracing.Start(ctx, "BankAdapter.CreateAccount")
+	defer span.End()
+
+	if err := acct.Validate(); err != nil {
+		return bank.Account{}, tracing.Error(span, err)
+	}
 
-	exchangeRateRequest := protogenbank.ExchangeRateRequest{
-		FromCurrency: fromCurr,
-		ToCurrency:   toCurr,
+	accountRequest := protogenbank.AccountRequest{
+		AccountName:          acct.Name,
+		Currency:             acct.Currency,
+		InitialDepositAmount: acct.InitialDepositAmount,
 	}
 
-	exchangeRateStream, err := adapter.bankClient.FetchExchangeRates(ctx, &exchangeRateRequest)
+	res, err := adapter.bankClient.CreateAccount(ctx, &accountRequest)
 	if err != nil {
-		st, _ := status.FromError(err)
-		log.Fatalf("[FATAL] Error fetching the exchange rates from %v to %v with error %v\n", fromCurr, toCurr, st)
+		return bank.Account{}, tracing.Error(span, rpcerror.New("CreateAccount", err))
 	}
 
-	for {
-		rates, err := exchangeRateStream.Recv()
-		if err == io.EOF {
-			break
+	acct.UUID = res.AccountUuid
+	return acct, nil
+}
+
+// FetchExchangeRates streams the rates from fromCurr to toCurr. The stream is
+// opened when iteration starts and closed when the loop body stops early, the
+// context is cancelled or the server ends the stream.
+func (adapter BankAdapter) FetchExchangeRates(ctx context.Context, fromCurr, toCurr string) iter.Seq2[bank.ExchangeRate, error] {
+	return func(yield func(bank.ExchangeRate, error) bool) {
+		ctx, span := tracing.Start(ctx, "BankAdapter.FetchExchangeRates")
+		defer span.End()
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		exchangeRateRequest := protogenbank.ExchangeRateRequest{
+			FromCurrency: fromCurr,
+			ToCurrency:   toCurr,
 		}
+
+		exchangeRateStream, err := adapter.bankClient.FetchExchangeRates(ctx, &exchangeRateRequest)
 		if err != nil {
-			st, _ := status.FromError(err)
-			if st.Code() == codes.InvalidArgument {
-				log.Fatalln("[FATAL] Error on FetchExchangeRates: \n", st.Message())
+			yield(bank.ExchangeRate{}, tracing.Error(span, rpcerror.New("FetchExchangeRates", err)))
+			return
+		}
 
+		for {
+			rates, err := exchangeRateStream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(bank.ExchangeRate{}, tracing.Error(span, rpcerror.New("FetchExchangeRates", err)))
+				return
 			}
-		}
 
-		log.Printf("Rates at %v from %v to %v: %v\n ", rates.Timestamp, fromCurr, toCurr, rates.Rate)
+			if !yield(toExchangeRate(rates), nil) {
+				return
+			}
+		}
 	}
 }
 
-func (adapter BankAdapter) SummarizeTransactions(ctx context.Context, acct string, txs []bank.Transaction) {
+func (adapter BankAdapter) SummarizeTransactions(ctx context.Context, acct string, txs []bank.Transaction) (bank.TransactionSummary, error) {
+	ctx, span := tracing.Start(ctx, "BankAdapter.SummarizeTransactions")
+	defer span.End()
 
 	txStream, err := adapter.bankClient.SummarizeTransactions(ctx)
 	if err != nil {
-		log.Fatalln("[FATAL] Error on SummarizeTransactions: ", err)
+		return bank.TransactionSummary{}, tracing.Error(span, rpcerror.New("SummarizeTransactions", err))
 	}
 
 	for _, tx := range txs {
@@ -90,74 +133,76 @@ func (adapter BankAdapter) SummarizeTransactions(ctx context.Context, acct strin
 			Amount:        tx.Amount,
 			Notes:         tx.Notes,
 		}
-		txStream.Send(&transactionRequest)
+		// On a send failure the real status is reported by CloseAndRecv
+		if err := txStream.Send(&transactionRequest); err != nil {
+			break
+		}
 	}
 
 	summary, err := txStream.CloseAndRecv()
 	if err != nil {
-		st, _ := status.FromError(err)
-		log.Fatalf("[FATAL] Error receiving server stream response: %v\n", st)
+		return bank.TransactionSummary{}, tracing.Error(span, rpcerror.New("SummarizeTransactions", err))
 	}
-	log.Println("Summary details: ", summary)
+	return toTransactionSummary(summary), nil
 }
 
-func (adapter BankAdapter) TransferMultiple(ctx context.Context, trf []bank.TransferTransaction) {
+// TransferMultiple sends all transfers on one stream. When the server rejects
+// them the returned result carries the decoded failure reasons along with
+// the error.
+func (adapter BankAdapter) TransferMultiple(ctx context.Context, trf []bank.TransferTransaction) (bank.TransferResult, error) {
+	ctx, span := tracing.Start(ctx, "BankAdapter.TransferMultiple")
+	defer span.End()
 
 	clienttxsStream, err := adapter.bankClient.TransferMultiple(ctx)
 	if err != nil {
-		log.Fatalf("[FATAL] Error in TransferMultiple: %v\n", err)
+		return bank.TransferResult{}, tracing.Error(span, rpcerror.New("TransferMultiple", err))
 	}
-	trfChan := make(chan chan struct{})
 
-	go func() {
-		for _, tr := range trf {
-			req := &protogenbank.TransferRequest{
-				FromAccountNumber: tr.FromAccountNumber,
-				ToAccountNumber:   tr.ToAccountNumber,
-				Current:           tr.Currency,
-				Amount:            float32(tr.Amount),
-			}
-			clienttxsStream.Send(req)
+	for _, tr := range trf {
+		req := &protogenbank.TransferRequest{
+			FromAccountNumber: tr.FromAccountNumber,
+			ToAccountNumber:   tr.ToAccountNumber,
+			Current:           tr.Currency,
+			Amount:            float32(tr.Amount),
 		}
-		clienttxsStream.CloseSend()
-	}()
-
-	go func() {
-		for {
-			res, err := clienttxsStream.CloseAndRecv()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				handleTransferErrorGrpc(err)
-				break
-			} else {
-				log.Printf("Transfer status %v on %v", res.Status, res.Timestamp)
-			}
+		// On a send failure the real status is reported by CloseAndRecv
+		if err := clienttxsStream.Send(req); err != nil {
+			break
 		}
-		close(trfChan)
-	}()
-
-	<-trfChan
+	}
 
+	res, err := clienttxsStream.CloseAndRecv()
+	if err != nil {
+		err = tracing.Error(span, handleTransferErrorGrpc(err))
+		return bank.TransferResult{
+			Status:   bank.TransferStatusFailed,
+			Failures: transferFailures(err),
+		}, err
+	}
+	return toTransferResult(res), nil
 }
 
-func handleTransferErrorGrpc(err error) {
-	st := status.Convert(err)
+func handleTransferErrorGrpc(err error) error {
+	err = rpcerror.New("TransferMultiple", err)
+	var rpcErr *rpcerror.Error
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+	redactor := redact.Default()
 
-	log.Printf("Error %v on TransferMultiple : %v", st.Code(), st.Message())
+	slog.Warn("TransferMultiple failed", "code", rpcErr.Code().String(), "error", redactor.String(rpcErr.Status.Message()))
 
-	for _, detail := range st.Details() {
-		switch t := detail.(type) {
-		case *errdetails.PreconditionFailure:
-			for _, violation := range t.GetViolations() {
-				log.Println("[VIOLATION]", violation)
-			}
-		case *errdetails.ErrorInfo:
-			log.Printf("Error on : %v, with reason %v\n", t.Domain, t.Reason)
-			for k, v := range t.GetMetadata() {
-				log.Printf("  %v : %v\n", k, v)
-			}
+	if pf := rpcErr.Details.PreconditionFailure; pf != nil {
+		for _, violation := range pf.GetViolations() {
+			slog.Warn("Transfer precondition violated", "violation", redactor.Message(violation))
+		}
+	}
+	if info := rpcErr.Details.ErrorInfo; info != nil {
+		metadata := make([]any, 0, len(info.GetMetadata()))
+		for k, v := range info.GetMetadata() {
+			metadata = append(metadata, slog.String(k, redactor.KeyValue(k, v)))
 		}
+		slog.Warn("Transfer error info", "domain", info.Domain, "reason", info.Reason, slog.Group("metadata", metadata...))
 	}
+	return err
 }
@@ -0,0 +1,340 @@
+package bank_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	protogenbank "github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	bankadapter "github.com/VallabhSLEPAM/grpc-client/internal/adapter/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
+	"github.com/VallabhSLEPAM/grpc-client/internal/port/mock"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newFakeAdapter(t *testing.T) (*fakeserver.Server, bankadapter.BankAdapter) {
+	t.Helper()
+	srv := fakeserver.New()
+	t.Cleanup(srv.Close)
+	conn, err := srv.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	a, err := bankadapter.NewBankAdapter(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, a
+}
+
+func TestGetCurrentBalance(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+	srv.Bank.SetBalance("12345678", 250)
+
+	bal, err := a.GetCurrentBalance(context.Background(), "12345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bal.Amount != 250 {
+		t.Errorf("balance = %v, want 250", bal.Amount)
+	}
+
+	_, err = a.GetCurrentBalance(context.Background(), "87654321")
+	if !errors.Is(err, rpcerror.ErrAccountNotFound) {
+		t.Errorf("unknown account err = %v, want ErrAccountNotFound", err)
+	}
+	if code := rpcerror.Code(err); code != codes.NotFound {
+		t.Errorf("unknown account code = %v, want NotFound", code)
+	}
+}
+
+func TestCreateAccount(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+
+	acct, err := a.CreateAccount(context.Background(), bank.Account{Name: "Ann", Currency: "USD", InitialDepositAmount: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance, ok := srv.Bank.Balance(acct.UUID); !ok || balance != 100 {
+		t.Errorf("balance of the new account = %v, %v, want 100, true", balance, ok)
+	}
+
+	// Accounts that fail validation never reach the server.
+	_, err = a.CreateAccount(context.Background(), bank.Account{Name: "Bob", Currency: "dollars"})
+	if !errors.Is(err, bank.ErrInvalidAccount) {
+		t.Errorf("invalid account err = %v, want ErrInvalidAccount", err)
+	}
+	if n := len(srv.Bank.CreateAccount.Calls()); n != 1 {
+		t.Errorf("server saw %v calls, want 1", n)
+	}
+
+	srv.Bank.CreateAccount.Fail(fakeserver.InvalidCurrency("XXX"))
+	_, err = a.CreateAccount(context.Background(), bank.Account{Name: "Bob", Currency: "XXX"})
+	if !errors.Is(err, rpcerror.ErrInvalidCurrency) {
+		t.Errorf("rejected currency err = %v, want ErrInvalidCurrency", err)
+	}
+}
+
+func TestFetchExchangeRates(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+	ctx := context.Background()
+
+	var n int
+	for rate, err := range a.FetchExchangeRates(ctx, "USD", "EUR") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rate.From != "USD" || rate.To != "EUR" || rate.Timestamp.IsZero() {
+			t.Errorf("rate %v = %+v, want USD to EUR with a timestamp", n+1, rate)
+		}
+		n++
+	}
+	if n != fakeserver.StreamLength {
+		t.Errorf("got %v rates, want %v", n, fakeserver.StreamLength)
+	}
+
+	// Stopping early ends the stream without an error.
+	n = 0
+	for _, err := range a.FetchExchangeRates(ctx, "USD", "EUR") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n++; n == 2 {
+			break
+		}
+	}
+
+	// Timestamps are free-form, other formats are kept as sent.
+	srv.Bank.FetchExchangeRates.Respond(
+		&protogenbank.ExchangeRateResponse{FromCurrency: "USD", ToCurrency: "EUR", Rate: 0.9, Timestamp: "18 Oct 2026 09:00"},
+		&protogenbank.ExchangeRateResponse{FromCurrency: "USD", ToCurrency: "EUR", Rate: 0.91, Timestamp: "2026-10-18T09:01:00Z"},
+	)
+	var rates []bank.ExchangeRate
+	for rate, err := range a.FetchExchangeRates(ctx, "USD", "EUR") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		rates = append(rates, rate)
+	}
+	if len(rates) != 2 {
+		t.Fatalf("got %v rates, want 2", len(rates))
+	}
+	if rates[0].RawTimestamp != "18 Oct 2026 09:00" || !rates[0].Timestamp.IsZero() {
+		t.Errorf("rate with another format = %+v, want the raw timestamp and a zero time", rates[0])
+	}
+	if rates[1].Timestamp.IsZero() {
+		t.Errorf("rate with an RFC 3339 timestamp was not parsed: %+v", rates[1])
+	}
+
+	for _, err := range a.FetchExchangeRates(ctx, "USD", "euro") {
+		if !errors.Is(err, rpcerror.ErrInvalidCurrency) {
+			t.Errorf("invalid currency err = %v, want ErrInvalidCurrency", err)
+		}
+	}
+}
+
+func TestSummarizeTransactions(t *testing.T) {
+	_, a := newFakeAdapter(t)
+
+	summary, err := a.SummarizeTransactions(context.Background(), "12345678", []bank.Transaction{
+		{Amount: 100, TransactionType: bank.TransactionTypeIn},
+		{Amount: 30, TransactionType: bank.TransactionTypeOut},
+		{Amount: 5, TransactionType: bank.TransactionTypeIn},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.AccountNumber != "12345678" || summary.SumAmountIn != 105 || summary.SumAmountOut != 30 || summary.SumTotal != 75 {
+		t.Errorf("summary = %+v, want 105 in, 30 out, 75 total", summary)
+	}
+}
+
+func TestTransferMultiple(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+	srv.Bank.SetBalance("11111111", 100)
+	srv.Bank.SetBalance("22222222", 0)
+
+	res, err := a.TransferMultiple(context.Background(), []bank.TransferTransaction{
+		{FromAccountNumber: "11111111", ToAccountNumber: "22222222", Currency: "USD", Amount: 60},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != bank.TransferStatusSuccess || res.Amount != 60 {
+		t.Errorf("result = %+v, want a successful transfer of 60", res)
+	}
+
+	res, err = a.TransferMultiple(context.Background(), []bank.TransferTransaction{
+		{FromAccountNumber: "11111111", ToAccountNumber: "22222222", Currency: "USD", Amount: 60},
+	})
+	if !errors.Is(err, rpcerror.ErrInsufficientFunds) {
+		t.Fatalf("overdraft err = %v, want ErrInsufficientFunds", err)
+	}
+	if res.Status != bank.TransferStatusFailed {
+		t.Errorf("overdraft status = %v, want %v", res.Status, bank.TransferStatusFailed)
+	}
+	var found bool
+	for _, f := range res.Failures {
+		if f.Subject == "11111111" && f.Reason == "INSUFFICIENT_FUNDS" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("failures = %+v, want the precondition failure of 11111111", res.Failures)
+	}
+	if balance, _ := srv.Bank.Balance("11111111"); balance != 40 {
+		t.Errorf("balance after the rejected transfer = %v, want 40", balance)
+	}
+}
+
+func TestGetCurrentBalanceWithMock(t *testing.T) {
+	client := &mock.BankClient{
+		GetCurrentBalanceFunc: func(ctx context.Context, in *protogenbank.CurrentBalanceRequest, opts ...grpc.CallOption) (*protogenbank.CurrentBalanceResponse, error) {
+			if in.GetAccountNumber() != "12345678" {
+				return nil, status.Error(codes.NotFound, "no such account")
+			}
+			return &protogenbank.CurrentBalanceResponse{Amount: 42}, nil
+		},
+	}
+	a := bankadapter.NewBankAdapterFromClient(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	bal, err := a.GetCurrentBalance(ctx, "12345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bal.GetAmount() != 42 {
+		t.Errorf("balance = %v, want 42", bal.GetAmount())
+	}
+
+	calls := client.Calls()
+	if len(calls) != 1 || calls[0].Method != "GetCurrentBalance" {
+		t.Fatalf("calls = %+v, want one GetCurrentBalance", calls)
+	}
+	if want, _ := ctx.Deadline(); !calls[0].Deadline.Equal(want) {
+		t.Errorf("call deadline = %v, want %v", calls[0].Deadline, want)
+	}
+}
+
+func TestStreamsWithMock(t *testing.T) {
+	summaries := &mock.ClientStream[protogenbank.Transaction, protogenbank.TransactionSummary]{
+		Response: &protogenbank.TransactionSummary{AccountNumber: "12345678", SumAmountIn: 100, SumAmountOut: 30, SumTotal: 70},
+	}
+	rates := &mock.ServerStream[protogenbank.ExchangeRateResponse]{
+		Responses: []*protogenbank.ExchangeRateResponse{{FromCurrency: "USD", ToCurrency: "EUR", Rate: 0.9}},
+		Err:       status.Error(codes.Unavailable, "connection reset"),
+	}
+	client := &mock.BankClient{
+		SummarizeTransactionsFunc: func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[protogenbank.Transaction, protogenbank.TransactionSummary], error) {
+			return summaries, nil
+		},
+		FetchExchangeRatesFunc: func(ctx context.Context, in *protogenbank.ExchangeRateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[protogenbank.ExchangeRateResponse], error) {
+			return rates, nil
+		},
+	}
+	a := bankadapter.NewBankAdapterFromClient(client)
+	ctx := context.Background()
+
+	summary, err := a.SummarizeTransactions(ctx, "12345678", []bank.Transaction{
+		{Amount: 100, TransactionType: bank.TransactionTypeIn},
+		{Amount: 30, TransactionType: bank.TransactionTypeOut},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.SumTotal != 70 {
+		t.Errorf("summary total = %v, want 70", summary.SumTotal)
+	}
+	sent := summaries.Sent()
+	if len(sent) != 2 || sent[0].GetType() != protogenbank.TransactionType_TRANSACTION_TYPE_IN || sent[1].GetType() != protogenbank.TransactionType_TRANSACTION_TYPE_OUT {
+		t.Errorf("sent %v, want one transaction in then one out", sent)
+	}
+
+	var got []error
+	for _, err := range a.FetchExchangeRates(ctx, "USD", "EUR") {
+		got = append(got, err)
+	}
+	if len(got) != 2 || got[0] != nil || rpcerror.Code(got[1]) != codes.Unavailable {
+		t.Errorf("stream errors = %v, want a rate then Unavailable", got)
+	}
+
+	// A stream that fails to send still reports the status of CloseAndRecv.
+	summaries.SendErr = io.EOF
+	summaries.Err = status.Error(codes.InvalidArgument, "bad transaction")
+	_, err = a.SummarizeTransactions(ctx, "12345678", []bank.Transaction{{Amount: 1}})
+	if code := rpcerror.Code(err); code != codes.InvalidArgument {
+		t.Errorf("code = %v, want InvalidArgument", code)
+	}
+
+	if _, err := a.CreateAccount(ctx, bank.Account{Name: "Ann", Currency: "USD"}); rpcerror.Code(err) != codes.Unimplemented {
+		t.Errorf("unset method err = %v, want Unimplemented", err)
+	}
+}
+
+func TestRateLimitedDetails(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+	st, err := fakeserver.Status(codes.ResourceExhausted, "slow down", &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Bank.GetCurrentBalance.Fail(st.Err())
+
+	_, err = a.GetCurrentBalance(context.Background(), "12345678")
+	var rpcErr *rpcerror.Error
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("err = %v, want an *rpcerror.Error", err)
+	}
+	if delay, ok := rpcErr.Details.RetryDelay(); !ok || delay != time.Second {
+		t.Errorf("retry delay = %v, %v, want 1s, true", delay, ok)
+	}
+
+	if _, err := fakeserver.Status(codes.OK, "fine", &errdetails.RetryInfo{}); err == nil {
+		t.Error("details on an OK status were attached")
+	}
+}
+
+func TestErrorsAreRecordedOnSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	srv, a := newFakeAdapter(t)
+	srv.Bank.SetBalance("11111111", 10)
+	ctx := context.Background()
+
+	if _, err := a.CreateAccount(ctx, bank.Account{Name: "Bob", Currency: "dollars"}); err == nil {
+		t.Fatal("invalid account was accepted")
+	}
+	if _, err := a.TransferMultiple(ctx, []bank.TransferTransaction{
+		{FromAccountNumber: "11111111", ToAccountNumber: "22222222", Currency: "USD", Amount: 60},
+	}); err == nil {
+		t.Fatal("overdraft was accepted")
+	}
+
+	failed := make(map[string]bool)
+	for _, span := range recorder.Ended() {
+		if span.Status().Code == otelcodes.Error {
+			failed[span.Name()] = true
+		}
+	}
+	for _, name := range []string{"BankAdapter.CreateAccount", "BankAdapter.TransferMultiple"} {
+		if !failed[name] {
+			t.Errorf("span %v was not marked as failed", name)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package bank
+
+import (
+	"errors"
+	"time"
+
+	protogenbank "github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
+	"google.golang.org/genproto/googleapis/type/date"
+	"google.golang.org/genproto/googleapis/type/datetime"
+)
+
+// toExchangeRate keeps the timestamp as sent, the field is free-form.
+func toExchangeRate(res *protogenbank.ExchangeRateResponse) bank.ExchangeRate {
+	ts, _ := time.Parse(time.RFC3339, res.Timestamp)
+	return bank.ExchangeRate{
+		From:         res.FromCurrency,
+		To:           res.ToCurrency,
+		Rate:         res.Rate,
+		RawTimestamp: res.Timestamp,
+		Timestamp:    ts,
+	}
+}
+
+func toTransactionSummary(res *protogenbank.TransactionSummary) bank.TransactionSummary {
+	return bank.TransactionSummary{
+		AccountNumber:   res.AccountNumber,
+		SumAmountIn:     res.SumAmountIn,
+		SumAmountOut:    res.SumAmountOut,
+		SumTotal:        res.SumTotal,
+		TransactionDate: fromDate(res.TransactionDate),
+	}
+}
+
+func toTransferResult(res *protogenbank.TransferResponse) bank.TransferResult {
+	st := bank.TransferStatusUnspecified
+	switch res.Status {
+	case protogenbank.TransferStatus_TRANSFER_STATUS_SUCCESS:
+		st = bank.TransferStatusSuccess
+	case protogenbank.TransferStatus_TRANSFER_STATUS_FAILED:
+		st = bank.TransferStatusFailed
+	}
+
+	return bank.TransferResult{
+		FromAccountNumber: res.FromAccountNumber,
+		ToAccountNumber:   res.ToAccountNumber,
+		Currency:          res.Current,
+		Amount:            res.Amount,
+		Status:            st,
+		Timestamp:         fromDateTime(res.Timestamp),
+	}
+}
+
+// transferFailures lists the reasons carried by the PreconditionFailure,
+// BadRequest and ErrorInfo details of a failed TransferMultiple call.
+func transferFailures(err error) []bank.TransferFailure {
+	var rpcErr *rpcerror.Error
+	if !errors.As(err, &rpcErr) {
+		return nil
+	}
+
+	var failures []bank.TransferFailure
+
+	if pf := rpcErr.Details.PreconditionFailure; pf != nil {
+		for _, v := range pf.GetViolations() {
+			failures = append(failures, bank.TransferFailure{
+				Subject:     v.GetSubject(),
+				Reason:      v.GetType(),
+				Description: v.GetDescription(),
+			})
+		}
+	}
+	if br := rpcErr.Details.BadRequest; br != nil {
+		for _, v := range br.GetFieldViolations() {
+			failures = append(failures, bank.TransferFailure{
+				Subject:     v.GetField(),
+				Reason:      v.GetReason(),
+				Description: v.GetDescription(),
+			})
+		}
+	}
+	if info := rpcErr.Details.ErrorInfo; info != nil {
+		failures = append(failures, bank.TransferFailure{
+			Subject:     info.GetDomain(),
+			Reason:      info.GetReason(),
+			Description: rpcErr.Status.Message(),
+		})
+	}
+	return failures
+}
+
+func fromDate(d *date.Date) time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	return time.Date(int(d.Year), time.Month(d.Month), int(d.Day), 0, 0, 0, 0, time.UTC)
+}
+
+func fromDateTime(dt *datetime.DateTime) time.Time {
+	if dt == nil {
+		return time.Time{}
+	}
+
+	loc := time.UTC
+	if offset := dt.GetUtcOffset(); offset != nil {
+		loc = time.FixedZone("", int(offset.AsDuration().Seconds()))
+	} else if tz := dt.GetTimeZone(); tz != nil {
+		if l, err := time.LoadLocation(tz.Id); err == nil {
+			loc = l
+		}
+	}
+	return time.Date(int(dt.Year), time.Month(dt.Month), int(dt.Day),
+		int(dt.Hours), int(dt.Minutes), int(dt.Seconds), int(dt.Nanos), loc)
+}
@@ -3,11 +3,13 @@ package adapter
 import (
 	"context"
 	"io"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
 	"github.com/VallabhSLEPAM/grpc-client/internal/port"
+	"github.com/VallabhSLEPAM/grpc-client/internal/tracing"
 	"google.golang.org/grpc"
 )
 
@@ -18,108 +20,140 @@ type HelloAdapter struct {
 // Just a wrapper to create service client and return it
 func NewHelloAdapter(conn *grpc.ClientConn) (*HelloAdapter, error) {
 	client := hello.NewHelloServiceClient(conn)
+	return NewHelloAdapterFromClient(client), nil
+}
+
+// NewHelloAdapterFromClient builds the adapter on any implementation of the
+// port, such as the mocks of package port/mock.
+func NewHelloAdapterFromClient(client port.HelloClientPort) *HelloAdapter {
 	return &HelloAdapter{
 		helloClient: client,
-	}, nil
+	}
 }
 
 func (a *HelloAdapter) SayHello(ctx context.Context, name string) (*hello.HelloResponse, error) {
+	ctx, span := tracing.Start(ctx, "HelloAdapter.SayHello")
+	defer span.End()
+
 	helloRequest := &hello.HelloRequest{
-		Name: "Vallabh",
+		Name: name,
 	}
 
 	greet, err := a.helloClient.SayHello(ctx, helloRequest)
 	if err != nil {
-		log.Fatal("Error on SayHello: ", err)
+		return nil, tracing.Error(span, rpcerror.New("SayHello", err))
 	}
 	return greet, nil
 
 }
 
-func (a *HelloAdapter) SayHelloServerStream(ctx context.Context, name string) {
+func (a *HelloAdapter) SayHelloServerStream(ctx context.Context, name string) error {
+	ctx, span := tracing.Start(ctx, "HelloAdapter.SayHelloServerStream")
+	defer span.End()
+
 	helloRequest := &hello.HelloRequest{
-		Name: "Vallabh",
+		Name: name,
 	}
 
 	// Making the server RPC call
 	greetStream, err := a.helloClient.HelloServerStream(ctx, helloRequest)
 	if err != nil {
-		log.Fatalln("Error on SayHelloServerStream: ", err)
+		return tracing.Error(span, rpcerror.New("SayHelloServerStream", err))
 	}
 
 	for {
 		greet, err := greetStream.Recv()
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			log.Fatalln("Error on HelloServerStream while receiving: ", err)
+			return tracing.Error(span, rpcerror.New("SayHelloServerStream", err))
 		}
-		log.Println(greet.Greet)
+		slog.Info("Greeting received", "method", "HelloServerStream", "greet", greet.Greet)
 
 	}
 }
 
-func (a *HelloAdapter) SayHelloClientStream(ctx context.Context, names []string) {
+// SayHelloClientStream sends names one by one, pausing interval between
+// them, and logs the single greeting.
+func (a *HelloAdapter) SayHelloClientStream(ctx context.Context, names []string, interval time.Duration) error {
+	ctx, span := tracing.Start(ctx, "HelloAdapter.SayHelloClientStream")
+	defer span.End()
 
 	greetStream, err := a.helloClient.HelloClientStream(ctx)
 	if err != nil {
-		log.Fatalln("Error on SayHelloClientStream: ", err)
+		return tracing.Error(span, rpcerror.New("SayHelloClientStream", err))
 	}
 
-	for _, name := range names {
+	for i, name := range names {
+		if i > 0 && interval > 0 {
+			if err := sleep(ctx, interval); err != nil {
+				// CloseAndRecv reports the cancellation with its status
+				break
+			}
+		}
 		req := &hello.HelloRequest{
 			Name: name,
 		}
-		err = greetStream.Send(req)
-		if err != nil {
-			log.Fatalf("Error sending request with message: %v. err: %v\n", req, err)
+		// On a send failure the real status is reported by CloseAndRecv
+		if err := greetStream.Send(req); err != nil {
+			break
 		}
-		time.Sleep(500 * time.Millisecond)
 	}
 
 	resp, err := greetStream.CloseAndRecv()
 	if err != nil {
-		log.Fatalln("Error getting response in SayHelloClientStream: ", err)
+		return tracing.Error(span, rpcerror.New("SayHelloClientStream", err))
 	}
-	log.Println(resp.Greet)
+	slog.Info("Greeting received", "method", "HelloClientStream", "greet", resp.Greet)
+	return nil
 
 }
 
-func (a *HelloAdapter) SayHelloContinuous(ctx context.Context, names []string) {
+func (a *HelloAdapter) SayHelloContinuous(ctx context.Context, names []string) error {
+	ctx, span := tracing.Start(ctx, "HelloAdapter.SayHelloContinuous")
+	defer span.End()
 
 	stream, err := a.helloClient.HelloContinuous(ctx)
 	if err != nil {
-		log.Fatalf("Error getting client stream: %v\n", err)
+		return tracing.Error(span, rpcerror.New("SayHelloContinuous", err))
 	}
 
-	greetChan := make(chan struct{})
 	go func() {
 
 		for _, name := range names {
-			stream.Send(&hello.HelloRequest{
+			// On a send failure the real status is reported by Recv
+			if err := stream.Send(&hello.HelloRequest{
 				Name: name,
-			})
+			}); err != nil {
+				return
+			}
 		}
 		stream.CloseSend()
 	}()
 
-	go func() {
-
-		for {
-			resp, err := stream.Recv()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				log.Fatalf("Error getting response from server stream: %v\n", err)
-			}
-			log.Println("Received response from server stream:", resp.Greet)
-
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
 		}
-		close(greetChan)
-	}()
+		if err != nil {
+			return tracing.Error(span, rpcerror.New("SayHelloContinuous", err))
+		}
+		slog.Info("Greeting received", "method", "HelloContinuous", "greet", resp.Greet)
+
+	}
 
-	<-greetChan
+}
 
+// sleep waits for d unless ctx ends first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -0,0 +1,146 @@
+package adapter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	adapter "github.com/VallabhSLEPAM/grpc-client/internal/adapter/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
+	"github.com/VallabhSLEPAM/grpc-client/internal/port/mock"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newFakeAdapter(t *testing.T) (*fakeserver.Server, *adapter.HelloAdapter) {
+	t.Helper()
+	srv := fakeserver.New()
+	t.Cleanup(srv.Close)
+	conn, err := srv.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	a, err := adapter.NewHelloAdapter(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, a
+}
+
+func requestNames(calls []fakeserver.Call[hello.HelloRequest]) []string {
+	var names []string
+	for _, call := range calls {
+		for _, req := range call.Requests {
+			names = append(names, req.GetName())
+		}
+	}
+	return names
+}
+
+func TestSayHello(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+
+	resp, err := a.SayHello(context.Background(), "Ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetGreet() != "Hello Ann" {
+		t.Errorf("greet = %q, want %q", resp.GetGreet(), "Hello Ann")
+	}
+
+	srv.Hello.SayHello.Fail(status.Error(codes.Unavailable, "down"))
+	_, err = a.SayHello(context.Background(), "Ann")
+	if code := rpcerror.Code(err); code != codes.Unavailable {
+		t.Errorf("failed call code = %v, want Unavailable", code)
+	}
+}
+
+func TestSayHelloStreams(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+	ctx := context.Background()
+
+	if err := a.SayHelloServerStream(ctx, "Ann"); err != nil {
+		t.Errorf("server stream: %v", err)
+	}
+	srv.Hello.HelloServerStream.Fail(status.Error(codes.Internal, "broken"))
+	if err := a.SayHelloServerStream(ctx, "Ann"); rpcerror.Code(err) != codes.Internal {
+		t.Errorf("failed server stream err = %v, want Internal", err)
+	}
+
+	if err := a.SayHelloClientStream(ctx, []string{"Ann", "Bob"}, 0); err != nil {
+		t.Errorf("client stream: %v", err)
+	}
+	if names := requestNames(srv.Hello.HelloClientStream.Calls()); len(names) != 2 || names[1] != "Bob" {
+		t.Errorf("client stream sent %q, want [Ann Bob]", names)
+	}
+
+	if err := a.SayHelloContinuous(ctx, []string{"Ann", "Bob", "Cid"}); err != nil {
+		t.Errorf("bidi stream: %v", err)
+	}
+	if names := requestNames(srv.Hello.HelloContinuous.Calls()); len(names) != 3 {
+		t.Errorf("bidi stream sent %q, want 3 names", names)
+	}
+}
+
+func TestSayHelloWithMock(t *testing.T) {
+	client := &mock.HelloClient{
+		SayHelloFunc: func(ctx context.Context, in *hello.HelloRequest, opts ...grpc.CallOption) (*hello.HelloResponse, error) {
+			return &hello.HelloResponse{Greet: "Hi " + in.GetName()}, nil
+		},
+	}
+	a := adapter.NewHelloAdapterFromClient(client)
+
+	resp, err := a.SayHello(context.Background(), "Ann")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.GetGreet() != "Hi Ann" {
+		t.Errorf("greet = %q, want %q", resp.GetGreet(), "Hi Ann")
+	}
+	if calls := client.Calls(); len(calls) != 1 || !calls[0].Deadline.IsZero() {
+		t.Errorf("calls = %+v, want one without a deadline", calls)
+	}
+
+	if err := a.SayHelloServerStream(context.Background(), "Ann"); rpcerror.Code(err) != codes.Unimplemented {
+		t.Errorf("unset method err = %v, want Unimplemented", err)
+	}
+}
+
+func TestSayHelloContinuousWithMock(t *testing.T) {
+	stream := &mock.BidiStream[hello.HelloRequest, hello.HelloResponse]{
+		Responses: []*hello.HelloResponse{{Greet: "Hello Ann"}},
+	}
+	client := &mock.HelloClient{
+		HelloContinuousFunc: func(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[hello.HelloRequest, hello.HelloResponse], error) {
+			stream.Ctx = ctx
+			return stream, nil
+		},
+	}
+	a := adapter.NewHelloAdapterFromClient(client)
+
+	if err := a.SayHelloContinuous(context.Background(), []string{"Ann", "Bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if sent := stream.Sent(); len(sent) != 2 || sent[1].GetName() != "Bob" {
+		t.Errorf("sent %v, want Ann then Bob", sent)
+	}
+}
+
+func TestSayHelloClientStreamStopsPausingWithContext(t *testing.T) {
+	_, a := newFakeAdapter(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := a.SayHelloClientStream(ctx, []string{"Ann", "Bob"}, time.Hour)
+	if code := rpcerror.Code(err); code != codes.DeadlineExceeded {
+		t.Errorf("code = %v, want DeadlineExceeded", code)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the stream took %v to give up", elapsed)
+	}
+}
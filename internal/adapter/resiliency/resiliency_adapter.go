@@ -2,14 +2,21 @@ package resiliency
 
 import (
 	"context"
+	"errors"
 	"io"
-	"log"
+	"log/slog"
 
 	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/resiliency"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
 	"github.com/VallabhSLEPAM/grpc-client/internal/port"
+	"github.com/VallabhSLEPAM/grpc-client/internal/tracing"
 	"google.golang.org/grpc"
 )
 
+// ErrNoMetadataClient is returned by UnaryResiliencyWithMetadata when the
+// adapter was built without a metadata client.
+var ErrNoMetadataClient = errors.New("resiliency adapter has no metadata client")
+
 type ResiliencyAdapter struct {
 	resiliencyClientPort         port.ResiliencyClientPort
 	resiliencyMetadataClientPort port.ResiliencyMetadataClientPort
@@ -18,13 +25,23 @@ type ResiliencyAdapter struct {
 func NewResiliencyAdapter(conn *grpc.ClientConn) (*ResiliencyAdapter, error) {
 	client := resiliency.NewResiliencyServiceClient(conn)
 	clientMetadata := resiliency.NewResiliencyServiceWithMetadataClient(conn)
+	return NewResiliencyAdapterFromClients(client, clientMetadata), nil
+}
+
+// NewResiliencyAdapterFromClients builds the adapter on any implementation
+// of the ports, such as the mocks of package port/mock. metadataClient may be
+// nil, UnaryResiliencyWithMetadata then returns ErrNoMetadataClient.
+func NewResiliencyAdapterFromClients(client port.ResiliencyClientPort, metadataClient port.ResiliencyMetadataClientPort) *ResiliencyAdapter {
 	return &ResiliencyAdapter{
 		resiliencyClientPort:         client,
-		resiliencyMetadataClientPort: clientMetadata,
-	}, nil
+		resiliencyMetadataClientPort: metadataClient,
+	}
 }
 
 func (adapter ResiliencyAdapter) UnaryResiliency(ctx context.Context, minDelay, maxDelay int, statusCode []uint32) (*resiliency.ResiliencyResponse, error) {
+	ctx, span := tracing.Start(ctx, "ResiliencyAdapter.UnaryResiliency")
+	defer span.End()
+
 	resiliencyRequest := resiliency.ResiliencyRequest{
 		MinDelaySecond: int32(minDelay),
 		MaxDelaySecond: int32(maxDelay),
@@ -33,13 +50,15 @@ func (adapter ResiliencyAdapter) UnaryResiliency(ctx context.Context, minDelay,
 
 	res, err := adapter.resiliencyClientPort.UnaryResiliency(ctx, &resiliencyRequest)
 	if err != nil {
-		log.Println("Error on UnaryResiliency:", err)
-		return nil, err
+		return nil, tracing.Error(span, rpcerror.New("UnaryResiliency", err))
 	}
 	return res, nil
 }
 
-func (adapter ResiliencyAdapter) ServerResiliency(ctx context.Context, minDelay, maxDelay int, statusCode []uint32) {
+func (adapter ResiliencyAdapter) ServerResiliency(ctx context.Context, minDelay, maxDelay int, statusCode []uint32) error {
+	ctx, span := tracing.Start(ctx, "ResiliencyAdapter.ServerResiliency")
+	defer span.End()
+
 	resiliencyRequest := resiliency.ResiliencyRequest{
 		MinDelaySecond: int32(minDelay),
 		MaxDelaySecond: int32(maxDelay),
@@ -48,28 +67,30 @@ func (adapter ResiliencyAdapter) ServerResiliency(ctx context.Context, minDelay,
 
 	reslResp, err := adapter.resiliencyClientPort.ServerResiliency(ctx, &resiliencyRequest)
 	if err != nil {
-		log.Fatalln("Error on ServerResiliency:", err)
+		return tracing.Error(span, rpcerror.New("ServerResiliency", err))
 	}
 
 	for {
 		res, err := reslResp.Recv()
 		if err == io.EOF {
-			log.Println("Server request cancelled ServerResiliency")
-			break
+			slog.Info("Server ended the stream", "method", "ServerResiliency")
+			return nil
 
 		}
 		if err != nil {
-			log.Fatalln("Error on ServerResiliency:", err)
+			return tracing.Error(span, rpcerror.New("ServerResiliency", err))
 		}
-		log.Println("Response from server: ", res.DummyString)
+		slog.Info("Response received", "method", "ServerResiliency", "response", res.DummyString)
 	}
 }
 
-func (adapter ResiliencyAdapter) ClientResiliency(ctx context.Context, minDelay, maxDelay int, statusCode []uint32, count int) {
+func (adapter ResiliencyAdapter) ClientResiliency(ctx context.Context, minDelay, maxDelay int, statusCode []uint32, count int) error {
+	ctx, span := tracing.Start(ctx, "ResiliencyAdapter.ClientResiliency")
+	defer span.End()
 
 	respStream, err := adapter.resiliencyClientPort.ClientResiliency(ctx)
 	if err != nil {
-		log.Fatalln("Error on ClientResiliency:", err)
+		return tracing.Error(span, rpcerror.New("ClientResiliency", err))
 	}
 
 	for i := 0; i < count; i++ {
@@ -78,22 +99,27 @@ func (adapter ResiliencyAdapter) ClientResiliency(ctx context.Context, minDelay,
 			MaxDelaySecond: int32(maxDelay),
 			StatusCodes:    statusCode,
 		}
-		respStream.Send(&resiliencyRequest)
+		// On a send failure the real status is reported by CloseAndRecv
+		if err := respStream.Send(&resiliencyRequest); err != nil {
+			break
+		}
 	}
 	resp, err := respStream.CloseAndRecv()
 	if err != nil {
-		log.Fatalln("Error on ClientResiliency:", err)
+		return tracing.Error(span, rpcerror.New("ClientResiliency", err))
 	}
-	log.Println(resp.DummyString)
+	slog.Info("Response received", "method", "ClientResiliency", "response", resp.DummyString)
+	return nil
 }
 
-func (adapter ResiliencyAdapter) BiDirectionalResiliency(ctx context.Context, minDelay, maxDelay int, statusCode []uint32, count int) {
+func (adapter ResiliencyAdapter) BiDirectionalResiliency(ctx context.Context, minDelay, maxDelay int, statusCode []uint32, count int) error {
+	ctx, span := tracing.Start(ctx, "ResiliencyAdapter.BiDirectionalResiliency")
+	defer span.End()
 
 	respStream, err := adapter.resiliencyClientPort.BiDirectionalResiliency(ctx)
 	if err != nil {
-		log.Fatalln("Error on BiDirectionalResiliency:", err)
+		return tracing.Error(span, rpcerror.New("BiDirectionalResiliency", err))
 	}
-	respChan := make(chan struct{})
 	go func() {
 		for i := 0; i < count; i++ {
 			resiliencyRequest := resiliency.ResiliencyRequest{
@@ -101,24 +127,22 @@ func (adapter ResiliencyAdapter) BiDirectionalResiliency(ctx context.Context, mi
 				MaxDelaySecond: int32(maxDelay),
 				StatusCodes:    statusCode,
 			}
-			respStream.Send(&resiliencyRequest)
+			// On a send failure the real status is reported by Recv
+			if err := respStream.Send(&resiliencyRequest); err != nil {
+				return
+			}
 		}
 		respStream.CloseSend()
 	}()
 
-	go func() {
-		for {
-			res, err := respStream.Recv()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				log.Fatalln("Error on BiDirectionalResiliency:", err)
-			}
-			log.Printf(res.DummyString)
+	for {
+		res, err := respStream.Recv()
+		if err == io.EOF {
+			return nil
 		}
-		close(respChan)
-
-	}()
-	<-respChan
+		if err != nil {
+			return tracing.Error(span, rpcerror.New("BiDirectionalResiliency", err))
+		}
+		slog.Info("Response received", "method", "BiDirectionalResiliency", "response", res.DummyString)
+	}
 }
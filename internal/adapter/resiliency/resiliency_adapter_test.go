@@ -0,0 +1,241 @@
+package resiliency_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/resiliency"
+	adapter "github.com/VallabhSLEPAM/grpc-client/internal/adapter/resiliency"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
+	"github.com/VallabhSLEPAM/grpc-client/internal/correlation"
+	"github.com/VallabhSLEPAM/grpc-client/internal/port/mock"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newFakeAdapter(t *testing.T) (*fakeserver.Server, *adapter.ResiliencyAdapter) {
+	t.Helper()
+	srv := fakeserver.New()
+	t.Cleanup(srv.Close)
+	conn, err := srv.Dial()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	a, err := adapter.NewResiliencyAdapter(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, a
+}
+
+func TestUnaryResiliency(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+	ctx := context.Background()
+
+	if _, err := a.UnaryResiliency(ctx, 0, 0, []uint32{uint32(codes.OK)}); err != nil {
+		t.Errorf("OK status: %v", err)
+	}
+	_, err := a.UnaryResiliency(ctx, 0, 0, []uint32{uint32(codes.Unavailable)})
+	if code := rpcerror.Code(err); code != codes.Unavailable {
+		t.Errorf("code = %v, want Unavailable", code)
+	}
+
+	calls := srv.Resiliency.UnaryResiliency.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("server saw %v calls, want 2", len(calls))
+	}
+	if got := calls[1].Requests[0].GetStatusCodes(); len(got) != 1 || got[0] != uint32(codes.Unavailable) {
+		t.Errorf("status codes sent = %v, want [Unavailable]", got)
+	}
+}
+
+func TestResiliencyStreams(t *testing.T) {
+	_, a := newFakeAdapter(t)
+	ctx := context.Background()
+	ok := []uint32{uint32(codes.OK)}
+
+	if err := a.ServerResiliency(ctx, 0, 0, ok); err != nil {
+		t.Errorf("server stream: %v", err)
+	}
+	if err := a.ClientResiliency(ctx, 0, 0, ok, 3); err != nil {
+		t.Errorf("client stream: %v", err)
+	}
+	if err := a.BiDirectionalResiliency(ctx, 0, 0, ok, 3); err != nil {
+		t.Errorf("bidi stream: %v", err)
+	}
+	if err := a.ServerResiliency(ctx, 0, 0, []uint32{uint32(codes.Internal)}); rpcerror.Code(err) != codes.Internal {
+		t.Errorf("failing server stream err = %v, want Internal", err)
+	}
+}
+
+func TestUnaryResiliencyWithMetadata(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+	srv.ResiliencyWithMetadata.UnaryResiliencyWithMetadata.Push(fakeserver.Step[resiliency.ResiliencyResponse]{
+		Header:    metadata.Pairs("grpc-server-os", "plan9"),
+		Trailer:   metadata.Pairs("grpc-server-load", "low"),
+		Responses: []*resiliency.ResiliencyResponse{{DummyString: "ok"}},
+	})
+
+	ctx := correlation.WithID(context.Background(), "corr-1")
+	_, md, err := a.UnaryResiliencyWithMetadata(ctx, 0, 0, []uint32{uint32(codes.OK)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.CorrelationID != "corr-1" {
+		t.Errorf("correlation ID = %q, want corr-1", md.CorrelationID)
+	}
+	if got := metadata.MD(md.Header).Get("grpc-server-os"); len(got) != 1 || got[0] != "plan9" {
+		t.Errorf("header grpc-server-os = %q, want [plan9]", got)
+	}
+	if got := metadata.MD(md.Trailer).Get("grpc-server-load"); len(got) != 1 || got[0] != "low" {
+		t.Errorf("trailer grpc-server-load = %q, want [low]", got)
+	}
+
+	calls := srv.ResiliencyWithMetadata.UnaryResiliencyWithMetadata.Calls()
+	if got := calls[0].Metadata.Get(correlation.IDHeader); len(got) != 1 || got[0] != "corr-1" {
+		t.Errorf("sent %v = %q, want [corr-1]", correlation.IDHeader, got)
+	}
+}
+
+func TestStreamResiliencyWithMetadata(t *testing.T) {
+	srv, a := newFakeAdapter(t)
+	ctx := context.Background()
+	ok := []uint32{uint32(codes.OK)}
+	step := fakeserver.Step[resiliency.ResiliencyResponse]{
+		Header:    metadata.Pairs("grpc-server-os", "plan9"),
+		Responses: []*resiliency.ResiliencyResponse{{DummyString: "ok"}},
+	}
+
+	tests := []struct {
+		name   string
+		method *fakeserver.ResiliencyMethod
+		call   func() (string, metadata.MD, error)
+	}{
+		{"server", srv.Resiliency.ServerResiliency, func() (string, metadata.MD, error) {
+			md, err := a.ServerResiliencyWithMetadata(ctx, 0, 0, ok)
+			return md.CorrelationID, metadata.MD(md.Header), err
+		}},
+		{"client", srv.Resiliency.ClientResiliency, func() (string, metadata.MD, error) {
+			md, err := a.ClientResiliencyWithMetadata(ctx, 0, 0, ok, 2)
+			return md.CorrelationID, metadata.MD(md.Header), err
+		}},
+		{"bidi", srv.Resiliency.BiDirectionalResiliency, func() (string, metadata.MD, error) {
+			md, err := a.BiDirectionalResiliencyWithMetadata(ctx, 0, 0, ok, 2)
+			return md.CorrelationID, metadata.MD(md.Header), err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.method.Push(step)
+			id, header, err := tt.call()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := header.Get("grpc-server-os"); len(got) != 1 || got[0] != "plan9" {
+				t.Errorf("header grpc-server-os = %q, want [plan9]", got)
+			}
+			calls := tt.method.Calls()
+			if len(calls) != 1 {
+				t.Fatalf("server saw %v calls, want 1", len(calls))
+			}
+			if got := calls[0].Metadata.Get(correlation.IDHeader); id == "" || len(got) != 1 || got[0] != id {
+				t.Errorf("sent %v = %q, want [%v]", correlation.IDHeader, got, id)
+			}
+		})
+	}
+}
+
+func TestUnaryResiliencyWithMetadataMock(t *testing.T) {
+	metadataClient := &mock.ResiliencyMetadataClient{
+		UnaryResiliencyWithMetadataFunc: func(ctx context.Context, in *resiliency.ResiliencyRequest, opts ...grpc.CallOption) (*resiliency.ResiliencyResponse, error) {
+			mock.SetHeader(opts, metadata.Pairs("grpc-server-os", "plan9"))
+			mock.SetTrailer(opts, metadata.Pairs("grpc-server-load", "low"))
+			return &resiliency.ResiliencyResponse{DummyString: "ok"}, nil
+		},
+	}
+	a := adapter.NewResiliencyAdapterFromClients(&mock.ResiliencyClient{}, metadataClient)
+
+	ctx, cancel := context.WithTimeout(correlation.WithID(context.Background(), "corr-1"), time.Minute)
+	defer cancel()
+	_, md, err := a.UnaryResiliencyWithMetadata(ctx, 1, 2, []uint32{uint32(codes.OK)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := metadata.MD(md.Header).Get("grpc-server-os"); len(got) != 1 || got[0] != "plan9" {
+		t.Errorf("header grpc-server-os = %q, want [plan9]", got)
+	}
+	if got := metadata.MD(md.Trailer).Get("grpc-server-load"); len(got) != 1 || got[0] != "low" {
+		t.Errorf("trailer grpc-server-load = %q, want [low]", got)
+	}
+
+	calls := metadataClient.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("got %v calls, want 1", len(calls))
+	}
+	call := calls[0]
+	var header, trailer bool
+	for _, opt := range call.Options {
+		switch opt.(type) {
+		case grpc.HeaderCallOption:
+			header = true
+		case grpc.TrailerCallOption:
+			trailer = true
+		}
+	}
+	if !header || !trailer {
+		t.Errorf("call options = %v, want grpc.Header and grpc.Trailer", call.Options)
+	}
+	if want, _ := ctx.Deadline(); !call.Deadline.Equal(want) {
+		t.Errorf("call deadline = %v, want %v", call.Deadline, want)
+	}
+	if got := call.Metadata.Get(correlation.IDHeader); len(got) != 1 || got[0] != "corr-1" {
+		t.Errorf("sent %v = %q, want [corr-1]", correlation.IDHeader, got)
+	}
+}
+
+func TestStreamResiliencyWithMetadataMock(t *testing.T) {
+	header := metadata.Pairs("grpc-server-os", "plan9")
+	requests := &mock.ClientStream[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse]{
+		Err: status.Error(codes.ResourceExhausted, "slow down"),
+	}
+	requests.HeaderMD = header
+	requests.TrailerMD = metadata.Pairs("retry-after", "1")
+	client := &mock.ResiliencyClient{
+		ClientResiliencyFunc: func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse], error) {
+			return requests, nil
+		},
+	}
+	a := adapter.NewResiliencyAdapterFromClients(client, nil)
+
+	md, err := a.ClientResiliencyWithMetadata(context.Background(), 0, 0, nil, 3)
+	if code := rpcerror.Code(err); code != codes.ResourceExhausted {
+		t.Fatalf("code = %v, want ResourceExhausted", code)
+	}
+	if len(requests.Sent()) != 3 {
+		t.Errorf("sent %v requests, want 3", len(requests.Sent()))
+	}
+	if got := metadata.MD(md.Trailer).Get("retry-after"); len(got) != 1 || got[0] != "1" {
+		t.Errorf("trailer retry-after = %q, want [1]", got)
+	}
+	if got := metadata.MD(md.Header).Get("grpc-server-os"); len(got) != 1 {
+		t.Errorf("header grpc-server-os = %q, want [plan9]", got)
+	}
+	if calls := client.Calls(); len(calls) != 1 || calls[0].Method != "ClientResiliency" {
+		t.Errorf("calls = %+v, want one ClientResiliency", calls)
+	}
+}
+
+func TestUnaryResiliencyWithMetadataWithoutClient(t *testing.T) {
+	a := adapter.NewResiliencyAdapterFromClients(&mock.ResiliencyClient{}, nil)
+
+	_, _, err := a.UnaryResiliencyWithMetadata(context.Background(), 0, 0, nil)
+	if !errors.Is(err, adapter.ErrNoMetadataClient) {
+		t.Errorf("UnaryResiliencyWithMetadata() = %v, want ErrNoMetadataClient", err)
+	}
+}
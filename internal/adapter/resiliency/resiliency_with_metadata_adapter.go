@@ -2,130 +2,128 @@ package resiliency
 
 import (
 	"context"
-	"fmt"
 	"io"
-	"log"
-	"runtime"
-	"time"
+	"log/slog"
 
 	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/resiliency"
-	"github.com/google/uuid"
+	domain "github.com/VallabhSLEPAM/grpc-client/internal/application/domain/resiliency"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
+	"github.com/VallabhSLEPAM/grpc-client/internal/correlation"
+	"github.com/VallabhSLEPAM/grpc-client/internal/tracing"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
-func sampleRequestMetadata() metadata.MD {
-	md := map[string]string{
-		"grpc-client-time":  time.Now().Format("20:40:00"),
-		"grpc-client-os":    runtime.GOOS,
-		"grpc-request-uuid": uuid.New().String(),
+func callMetadata(correlationID string, header, trailer metadata.MD) domain.CallMetadata {
+	return domain.CallMetadata{
+		CorrelationID: correlationID,
+		Header:        header,
+		Trailer:       trailer,
 	}
-	return metadata.New(md)
 }
 
-func sampleResponseMetadata(md metadata.MD) {
-	if md.Len() == 0 {
-		fmt.Println("No metadata found.")
-	} else {
-		fmt.Println("Response metadata:")
+// streamMetadata reads the response metadata of a stream that ended, neither
+// call blocks then.
+func streamMetadata(correlationID string, stream grpc.ClientStream) domain.CallMetadata {
+	header, _ := stream.Header()
+	return callMetadata(correlationID, header, stream.Trailer())
+}
 
-		for k, v := range md {
-			fmt.Printf("Key: %v,Value:%v\n", k, v)
-		}
-	}
+func (adapter ResiliencyAdapter) UnaryResiliencyWithMetadata(ctx context.Context, minDelay, maxDelay int, statusCode []uint32) (*resiliency.ResiliencyResponse, domain.CallMetadata, error) {
+	ctx, span := tracing.Start(ctx, "ResiliencyAdapter.UnaryResiliencyWithMetadata")
+	defer span.End()
 
-}
+	if adapter.resiliencyMetadataClientPort == nil {
+		return nil, domain.CallMetadata{}, tracing.Error(span, ErrNoMetadataClient)
+	}
 
-func (adapter ResiliencyAdapter) UnaryResiliencyWithMetadata(ctx context.Context, minDelay, maxDelay int, statusCode []uint32) (*resiliency.ResiliencyResponse, error) {
 	resiliencyRequest := resiliency.ResiliencyRequest{
 		MinDelaySecond: int32(minDelay),
 		MaxDelaySecond: int32(maxDelay),
 		StatusCodes:    statusCode,
 	}
-	ctx = metadata.NewOutgoingContext(ctx, sampleRequestMetadata())
+	ctx, id := correlation.Outgoing(ctx)
 
-	var responseMetadata metadata.MD
-	res, err := adapter.resiliencyMetadataClientPort.UnaryResiliencyWithMetadata(ctx, &resiliencyRequest, grpc.Header(&responseMetadata))
+	var header, trailer metadata.MD
+	res, err := adapter.resiliencyMetadataClientPort.UnaryResiliencyWithMetadata(ctx, &resiliencyRequest, grpc.Header(&header), grpc.Trailer(&trailer))
 	if err != nil {
-		log.Println("Error on UnaryResiliencyWithMetadata:", err)
-		return nil, err
+		return nil, callMetadata(id, header, trailer), tracing.Error(span, rpcerror.New("UnaryResiliencyWithMetadata", err))
 	}
-	sampleResponseMetadata(responseMetadata)
-	return res, nil
+	return res, callMetadata(id, header, trailer), nil
 }
 
-func (adapter ResiliencyAdapter) ServerResiliencyWithMetadata(ctx context.Context, minDelay, maxDelay int, statusCode []uint32) {
+func (adapter ResiliencyAdapter) ServerResiliencyWithMetadata(ctx context.Context, minDelay, maxDelay int, statusCode []uint32) (domain.CallMetadata, error) {
+	ctx, span := tracing.Start(ctx, "ResiliencyAdapter.ServerResiliencyWithMetadata")
+	defer span.End()
+
 	resiliencyRequest := resiliency.ResiliencyRequest{
 		MinDelaySecond: int32(minDelay),
 		MaxDelaySecond: int32(maxDelay),
 		StatusCodes:    statusCode,
 	}
-	ctx = metadata.NewOutgoingContext(ctx, sampleRequestMetadata())
+	ctx, id := correlation.Outgoing(ctx)
 
 	reslResp, err := adapter.resiliencyClientPort.ServerResiliency(ctx, &resiliencyRequest)
 	if err != nil {
-		log.Fatalln("Error on ServerResiliencyWithMetadata:", err)
-	}
-
-	if respMetadata, err := reslResp.Header(); err == nil {
-		sampleResponseMetadata(respMetadata)
+		return callMetadata(id, nil, nil), tracing.Error(span, rpcerror.New("ServerResiliencyWithMetadata", err))
 	}
 
 	for {
 		res, err := reslResp.Recv()
 		if err == io.EOF {
-			log.Println("Server request cancelled ServerResiliencyWithMetadata")
-			break
+			slog.Info("Server ended the stream", "method", "ServerResiliencyWithMetadata")
+			return streamMetadata(id, reslResp), nil
 
 		}
 		if err != nil {
-			log.Fatalln("Error on ServerResiliencyWithMetadata:", err)
+			return streamMetadata(id, reslResp), tracing.Error(span, rpcerror.New("ServerResiliencyWithMetadata", err))
 		}
-		log.Println("Response from server: ", res.DummyString)
+		slog.Info("Response received", "method", "ServerResiliencyWithMetadata", "response", res.DummyString)
 	}
 }
 
-func (adapter ResiliencyAdapter) ClientResiliencyWithMetadata(ctx context.Context, minDelay, maxDelay int, statusCode []uint32, count int) {
+func (adapter ResiliencyAdapter) ClientResiliencyWithMetadata(ctx context.Context, minDelay, maxDelay int, statusCode []uint32, count int) (domain.CallMetadata, error) {
+	ctx, span := tracing.Start(ctx, "ResiliencyAdapter.ClientResiliencyWithMetadata")
+	defer span.End()
+
+	ctx, id := correlation.Outgoing(ctx)
 
 	respStream, err := adapter.resiliencyClientPort.ClientResiliency(ctx)
 	if err != nil {
-		log.Fatalln("Error on ClientResiliencyWithMetadata:", err)
+		return callMetadata(id, nil, nil), tracing.Error(span, rpcerror.New("ClientResiliencyWithMetadata", err))
 	}
 
 	for i := 0; i < count; i++ {
-
-		ctx = metadata.NewOutgoingContext(ctx, sampleRequestMetadata())
 		resiliencyRequest := resiliency.ResiliencyRequest{
 			MinDelaySecond: int32(minDelay),
 			MaxDelaySecond: int32(maxDelay),
 			StatusCodes:    statusCode,
 		}
-		respStream.Send(&resiliencyRequest)
-	}
-
-	if respMetadata, err := respStream.Header(); err == nil {
-		sampleResponseMetadata(respMetadata)
+		// On a send failure the real status is reported by CloseAndRecv
+		if err := respStream.Send(&resiliencyRequest); err != nil {
+			break
+		}
 	}
 
 	resp, err := respStream.CloseAndRecv()
 	if err != nil {
-		log.Fatalln("Error on ClientResiliencyWithMetadata:", err)
+		return streamMetadata(id, respStream), tracing.Error(span, rpcerror.New("ClientResiliencyWithMetadata", err))
 	}
-	log.Println(resp.DummyString)
+	slog.Info("Response received", "method", "ClientResiliencyWithMetadata", "response", resp.DummyString)
+	return streamMetadata(id, respStream), nil
 }
 
-func (adapter ResiliencyAdapter) BiDirectionalResiliencyWithMetadata(ctx context.Context, minDelay, maxDelay int, statusCode []uint32, count int) {
+func (adapter ResiliencyAdapter) BiDirectionalResiliencyWithMetadata(ctx context.Context, minDelay, maxDelay int, statusCode []uint32, count int) (domain.CallMetadata, error) {
+	ctx, span := tracing.Start(ctx, "ResiliencyAdapter.BiDirectionalResiliencyWithMetadata")
+	defer span.End()
+
+	ctx, id := correlation.Outgoing(ctx)
 
 	respStream, err := adapter.resiliencyClientPort.BiDirectionalResiliency(ctx)
 	if err != nil {
-		log.Fatalln("Error on BiDirectionalResiliencyWithMetadata:", err)
-	}
-
-	if respMetadata, err := respStream.Header(); err == nil {
-		sampleResponseMetadata(respMetadata)
+		return callMetadata(id, nil, nil), tracing.Error(span, rpcerror.New("BiDirectionalResiliencyWithMetadata", err))
 	}
 
-	respChan := make(chan struct{})
 	go func() {
 		for i := 0; i < count; i++ {
 			resiliencyRequest := resiliency.ResiliencyRequest{
@@ -133,25 +131,22 @@ func (adapter ResiliencyAdapter) BiDirectionalResiliencyWithMetadata(ctx context
 				MaxDelaySecond: int32(maxDelay),
 				StatusCodes:    statusCode,
 			}
-			ctx = metadata.NewOutgoingContext(ctx, sampleRequestMetadata())
-			respStream.Send(&resiliencyRequest)
+			// On a send failure the real status is reported by Recv
+			if err := respStream.Send(&resiliencyRequest); err != nil {
+				return
+			}
 		}
 		respStream.CloseSend()
 	}()
 
-	go func() {
-		for {
-			res, err := respStream.Recv()
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				log.Fatalln("Error on BiDirectionalResiliencyWithMetadata:", err)
-			}
-			log.Printf(res.DummyString)
+	for {
+		res, err := respStream.Recv()
+		if err == io.EOF {
+			return streamMetadata(id, respStream), nil
 		}
-		close(respChan)
-
-	}()
-	<-respChan
+		if err != nil {
+			return streamMetadata(id, respStream), tracing.Error(span, rpcerror.New("BiDirectionalResiliencyWithMetadata", err))
+		}
+		slog.Info("Response received", "method", "BiDirectionalResiliencyWithMetadata", "response", res.DummyString)
+	}
 }
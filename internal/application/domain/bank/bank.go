@@ -1,5 +1,12 @@
 package bank
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
 const (
 	TransactionTypeIn  string = "IN"
 	TransactionTypeOut string = "OUT"
@@ -17,3 +24,82 @@ type TransferTransaction struct {
 	Currency          string
 	Amount            float64
 }
+
+const (
+	TransferStatusUnspecified string = "UNSPECIFIED"
+	TransferStatusSuccess     string = "SUCCESS"
+	TransferStatusFailed      string = "FAILED"
+)
+
+type TransactionSummary struct {
+	AccountNumber   string
+	SumAmountIn     float64
+	SumAmountOut    float64
+	SumTotal        float64
+	TransactionDate time.Time
+}
+
+type TransferResult struct {
+	FromAccountNumber string
+	ToAccountNumber   string
+	Currency          string
+	Amount            float64
+	Status            string
+	Timestamp         time.Time
+
+	// Failures is filled from the status details when the transfer fails.
+	Failures []TransferFailure
+}
+
+// TransferFailure describes why a transfer was rejected by the server.
+type TransferFailure struct {
+	Subject     string
+	Reason      string
+	Description string
+}
+
+type ExchangeRate struct {
+	From string
+	To   string
+	Rate float64
+
+	// RawTimestamp is the timestamp as sent by the server. Timestamp is it
+	// parsed as RFC 3339, zero when it is in another format.
+	RawTimestamp string
+	Timestamp    time.Time
+}
+
+var ErrInvalidAccount = errors.New("invalid account")
+
+type Account struct {
+	UUID                 string
+	Name                 string
+	Currency             string
+	InitialDepositAmount float64
+}
+
+// Validate checks the account fields before it is sent to the server.
+func (a Account) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAccount)
+	}
+	if !isCurrencyCode(a.Currency) {
+		return fmt.Errorf("%w: currency %q must be a 3-letter upper case ISO 4217 code", ErrInvalidAccount, a.Currency)
+	}
+	if a.InitialDepositAmount < 0 {
+		return fmt.Errorf("%w: initial deposit %v must not be negative", ErrInvalidAccount, a.InitialDepositAmount)
+	}
+	return nil
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
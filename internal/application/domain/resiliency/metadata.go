@@ -0,0 +1,9 @@
+package resiliency
+
+// CallMetadata is what was exchanged alongside a call besides the messages.
+// Header and Trailer are the response metadata sent by the server.
+type CallMetadata struct {
+	CorrelationID string
+	Header        map[string][]string
+	Trailer       map[string][]string
+}
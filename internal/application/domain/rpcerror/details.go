@@ -0,0 +1,75 @@
+package rpcerror
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// Details holds the standard google.rpc error details attached to a status.
+// A field is nil when the server did not send that detail type.
+type Details struct {
+	ErrorInfo           *errdetails.ErrorInfo
+	BadRequest          *errdetails.BadRequest
+	PreconditionFailure *errdetails.PreconditionFailure
+	RetryInfo           *errdetails.RetryInfo
+	QuotaFailure        *errdetails.QuotaFailure
+	ResourceInfo        *errdetails.ResourceInfo
+	LocalizedMessage    *errdetails.LocalizedMessage
+	DebugInfo           *errdetails.DebugInfo
+	Help                *errdetails.Help
+	RequestInfo         *errdetails.RequestInfo
+}
+
+func decodeDetails(st *status.Status) Details {
+	var d Details
+	for _, detail := range st.Details() {
+		switch t := detail.(type) {
+		case *errdetails.ErrorInfo:
+			d.ErrorInfo = t
+		case *errdetails.BadRequest:
+			d.BadRequest = t
+		case *errdetails.PreconditionFailure:
+			d.PreconditionFailure = t
+		case *errdetails.RetryInfo:
+			d.RetryInfo = t
+		case *errdetails.QuotaFailure:
+			d.QuotaFailure = t
+		case *errdetails.ResourceInfo:
+			d.ResourceInfo = t
+		case *errdetails.LocalizedMessage:
+			d.LocalizedMessage = t
+		case *errdetails.DebugInfo:
+			d.DebugInfo = t
+		case *errdetails.Help:
+			d.Help = t
+		case *errdetails.RequestInfo:
+			d.RequestInfo = t
+		}
+	}
+	return d
+}
+
+// DetailsOf returns the details of err, be it an *Error or any error
+// carrying a gRPC status.
+func DetailsOf(err error) Details {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Details
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return Details{}
+	}
+	return decodeDetails(st)
+}
+
+// RetryDelay returns the delay suggested by the server through RetryInfo.
+func (d Details) RetryDelay() (time.Duration, bool) {
+	if d.RetryInfo == nil || d.RetryInfo.GetRetryDelay() == nil {
+		return 0, false
+	}
+	return d.RetryInfo.GetRetryDelay().AsDuration(), true
+}
@@ -0,0 +1,65 @@
+package rpcerror
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	ErrAccountNotFound   = errors.New("account not found")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInvalidCurrency   = errors.New("invalid currency")
+	ErrRateLimited       = errors.New("rate limited")
+)
+
+// Reasons sent by the server in ErrorInfo. They take precedence over the
+// status code when classifying an error.
+var reasonKinds = map[string]error{
+	"ACCOUNT_NOT_FOUND":    ErrAccountNotFound,
+	"INSUFFICIENT_FUNDS":   ErrInsufficientFunds,
+	"INSUFFICIENT_BALANCE": ErrInsufficientFunds,
+	"INVALID_CURRENCY":     ErrInvalidCurrency,
+	"UNSUPPORTED_CURRENCY": ErrInvalidCurrency,
+	"RATE_LIMITED":         ErrRateLimited,
+	"RATE_LIMIT_EXCEEDED":  ErrRateLimited,
+}
+
+func classify(code codes.Code, d Details) error {
+	if d.ErrorInfo != nil {
+		if kind, ok := reasonKinds[strings.ToUpper(d.ErrorInfo.GetReason())]; ok {
+			return kind
+		}
+	}
+
+	switch code {
+	case codes.NotFound:
+		if d.ResourceInfo != nil && strings.Contains(strings.ToLower(d.ResourceInfo.GetResourceType()), "account") {
+			return ErrAccountNotFound
+		}
+	case codes.FailedPrecondition:
+		if d.PreconditionFailure != nil {
+			for _, v := range d.PreconditionFailure.GetViolations() {
+				if strings.Contains(strings.ToUpper(v.GetType()), "INSUFFICIENT") {
+					return ErrInsufficientFunds
+				}
+			}
+		}
+	case codes.InvalidArgument:
+		if d.BadRequest != nil {
+			for _, v := range d.BadRequest.GetFieldViolations() {
+				if strings.Contains(strings.ToLower(v.GetField()), "currency") {
+					return ErrInvalidCurrency
+				}
+			}
+		}
+	case codes.ResourceExhausted:
+		return ErrRateLimited
+	}
+
+	if d.QuotaFailure != nil {
+		return ErrRateLimited
+	}
+	return nil
+}
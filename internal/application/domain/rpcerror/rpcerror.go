@@ -0,0 +1,84 @@
+package rpcerror
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Error is returned by the adapters whenever an RPC fails. It keeps the gRPC
+// status along with the adapter operation that failed and the decoded
+// status details, so callers can use errors.Is against the Err* sentinels
+// and errors.As to reach the details.
+type Error struct {
+	Op      string
+	Status  *status.Status
+	Details Details
+
+	// Kind is the domain sentinel the status was classified as, or nil.
+	Kind error
+
+	// err is the error returned by the call, usually the status error
+	// itself.
+	err error
+}
+
+// New wraps err for the given operation. It returns nil when err is nil.
+func New(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	st := status.Convert(err)
+	details := decodeDetails(st)
+	return &Error{
+		Op:      op,
+		Status:  st,
+		Details: details,
+		Kind:    classify(st.Code(), details),
+		err:     err,
+	}
+}
+
+// Error includes the status message as sent. Servers tend to echo account
+// numbers back, so redact it before logging or printing.
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%v: %v: %v", e.Op, e.Status.Code(), e.Status.Message())
+	if e.Details.ErrorInfo != nil && e.Details.ErrorInfo.Reason != "" {
+		msg += fmt.Sprintf(" (reason %v)", e.Details.ErrorInfo.Reason)
+	}
+	return msg
+}
+
+// GRPCStatus lets status.FromError and status.Code see through the wrapper.
+func (e *Error) GRPCStatus() *status.Status {
+	return e.Status
+}
+
+// Unwrap returns the error of the call, so errors.As can reach errors
+// produced by the interceptors.
+func (e *Error) Unwrap() error {
+	if e.err == nil {
+		return e.Status.Err()
+	}
+	return e.err
+}
+
+// Is reports whether the error was classified as target.
+func (e *Error) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
+
+func (e *Error) Code() codes.Code {
+	return e.Status.Code()
+}
+
+// Code returns the gRPC code of err, looking through any wrapping.
+func Code(err error) codes.Code {
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code()
+	}
+	return status.Code(err)
+}
@@ -0,0 +1,137 @@
+package rpcerror_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func statusError(t *testing.T, code codes.Code, msg string, details ...protoadapt.MessageV1) error {
+	t.Helper()
+	st, err := status.New(code, msg).WithDetails(details...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return st.Err()
+}
+
+func TestKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    codes.Code
+		details []protoadapt.MessageV1
+		want    error
+	}{
+		{"reason wins over the code", codes.Internal, []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: "ACCOUNT_NOT_FOUND"}}, rpcerror.ErrAccountNotFound},
+		{"reason ignores case", codes.Unknown, []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: "insufficient_balance"}}, rpcerror.ErrInsufficientFunds},
+		{"unknown reason falls back to the code", codes.ResourceExhausted, []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: "SOMETHING_ELSE"}}, rpcerror.ErrRateLimited},
+		{"account resource", codes.NotFound, []protoadapt.MessageV1{&errdetails.ResourceInfo{ResourceType: "Account"}}, rpcerror.ErrAccountNotFound},
+		{"other resource", codes.NotFound, []protoadapt.MessageV1{&errdetails.ResourceInfo{ResourceType: "user"}}, nil},
+		{"insufficient precondition", codes.FailedPrecondition, []protoadapt.MessageV1{&errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{Type: "INSUFFICIENT_FUNDS"}},
+		}}, rpcerror.ErrInsufficientFunds},
+		{"currency field", codes.InvalidArgument, []protoadapt.MessageV1{&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "from_currency"}},
+		}}, rpcerror.ErrInvalidCurrency},
+		{"other field", codes.InvalidArgument, []protoadapt.MessageV1{&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "amount"}},
+		}}, nil},
+		{"quota failure", codes.Unavailable, []protoadapt.MessageV1{&errdetails.QuotaFailure{}}, rpcerror.ErrRateLimited},
+		{"resource exhausted", codes.ResourceExhausted, nil, rpcerror.ErrRateLimited},
+		{"bare code", codes.Unavailable, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rpcerror.New("Op", statusError(t, tt.code, "failed", tt.details...))
+			var rpcErr *rpcerror.Error
+			if !errors.As(err, &rpcErr) {
+				t.Fatalf("New returned %T, want *rpcerror.Error", err)
+			}
+			if rpcErr.Kind != tt.want {
+				t.Errorf("Kind = %v, want %v", rpcErr.Kind, tt.want)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(err, %v) = false", tt.want)
+			}
+			if rpcerror.Code(err) != tt.code {
+				t.Errorf("Code = %v, want %v", rpcerror.Code(err), tt.code)
+			}
+		})
+	}
+}
+
+func TestDetails(t *testing.T) {
+	info := &errdetails.ErrorInfo{Domain: "bank", Reason: "RATE_LIMITED"}
+	retry := &errdetails.RetryInfo{RetryDelay: durationpb.New(3 * time.Second)}
+	raw := statusError(t, codes.ResourceExhausted, "slow down", info, retry, &errdetails.Help{})
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"status error", raw},
+		{"rpcerror", rpcerror.New("Op", raw)},
+		{"wrapped rpcerror", fmt.Errorf("calling: %w", rpcerror.New("Op", raw))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := rpcerror.DetailsOf(tt.err)
+			if d.ErrorInfo.GetReason() != "RATE_LIMITED" || d.Help == nil || d.BadRequest != nil {
+				t.Errorf("details = %+v, want ErrorInfo and Help only besides RetryInfo", d)
+			}
+			if delay, ok := d.RetryDelay(); !ok || delay != 3*time.Second {
+				t.Errorf("RetryDelay = %v, %v, want 3s, true", delay, ok)
+			}
+		})
+	}
+
+	if d := rpcerror.DetailsOf(errors.New("plain")); d.ErrorInfo != nil {
+		t.Errorf("details of a plain error = %+v, want none", d)
+	}
+	if _, ok := (rpcerror.Details{RetryInfo: &errdetails.RetryInfo{}}).RetryDelay(); ok {
+		t.Error("RetryInfo without a delay reported one")
+	}
+}
+
+// callError stands for an error produced by an interceptor.
+type callError struct{ st *status.Status }
+
+func (e *callError) Error() string              { return "call failed" }
+func (e *callError) GRPCStatus() *status.Status { return e.st }
+
+func TestError(t *testing.T) {
+	if err := rpcerror.New("Op", nil); err != nil {
+		t.Errorf("New(nil) = %v, want nil", err)
+	}
+
+	err := rpcerror.New("GetCurrentBalance", statusError(t, codes.NotFound, "account 12345678 not found",
+		&errdetails.ErrorInfo{Reason: "ACCOUNT_NOT_FOUND"}))
+	want := "GetCurrentBalance: NotFound: account 12345678 not found (reason ACCOUNT_NOT_FOUND)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if err := rpcerror.New("Op", status.Error(codes.Internal, "boom")); err.Error() != "Op: Internal: boom" {
+		t.Errorf("Error() without reason = %q", err.Error())
+	}
+
+	cause := &callError{st: status.New(codes.Unavailable, "open")}
+	err = rpcerror.New("Op", cause)
+	var got *callError
+	if !errors.As(err, &got) || got != cause {
+		t.Errorf("errors.As did not reach the error of the call through Unwrap")
+	}
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("status.Code = %v, want Unavailable", status.Code(err))
+	}
+	if errors.Is(err, rpcerror.ErrAccountNotFound) {
+		t.Error("unclassified error matched ErrAccountNotFound")
+	}
+}
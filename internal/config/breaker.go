@@ -0,0 +1,96 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/sony/gobreaker"
+)
+
+const (
+	BreakerKeyMethod  = "method"
+	BreakerKeyService = "service"
+	BreakerKeyTarget  = "target"
+)
+
+// BreakerConfig configures the circuit breaker interceptors. Every breaker
+// trips once at least MinRequests were seen and the failure ratio is above
+// FailureRatio.
+type BreakerConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"`
+
+	// Name prefixes the name of every breaker.
+	Name string `yaml:"name" json:"name"`
+
+	// Key is "method", "service" or "target" and decides which calls share
+	// a breaker.
+	Key string `yaml:"key" json:"key"`
+
+	// FailureCodes lists the status codes counted as failures, empty means
+	// interceptor.DefaultBreakerFailureCodes.
+	FailureCodes []string `yaml:"failureCodes" json:"failureCodes"`
+
+	MaxRequests  uint32   `yaml:"maxRequests" json:"maxRequests"`
+	Interval     Duration `yaml:"interval" json:"interval"`
+	Timeout      Duration `yaml:"timeout" json:"timeout"`
+	FailureRatio float64  `yaml:"failureRatio" json:"failureRatio"`
+	MinRequests  uint32   `yaml:"minRequests" json:"minRequests"`
+}
+
+func (c BreakerConfig) validate() error {
+	var errs []error
+	if c.Name == "" {
+		errs = append(errs, errors.New("breaker.name is required"))
+	}
+	switch c.Key {
+	case BreakerKeyMethod, BreakerKeyService, BreakerKeyTarget:
+	default:
+		errs = append(errs, fmt.Errorf("breaker.key %q must be %q, %q or %q", c.Key, BreakerKeyMethod, BreakerKeyService, BreakerKeyTarget))
+	}
+	if _, err := ParseCodes(c.FailureCodes); err != nil {
+		errs = append(errs, fmt.Errorf("breaker.failureCodes: %w", err))
+	}
+	if c.FailureRatio <= 0 || c.FailureRatio > 1 {
+		errs = append(errs, fmt.Errorf("breaker.failureRatio %v must be in (0, 1]", c.FailureRatio))
+	}
+	if c.Interval < 0 || c.Timeout < 0 {
+		errs = append(errs, errors.New("breaker.interval and breaker.timeout must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// BreakerOptions converts the settings for the interceptors. The config is
+// expected to be valid.
+func (c BreakerConfig) BreakerOptions() interceptor.BreakerOptions {
+	key := interceptor.BreakerPerMethod
+	switch c.Key {
+	case BreakerKeyService:
+		key = interceptor.BreakerPerService
+	case BreakerKeyTarget:
+		key = interceptor.BreakerPerTarget
+	}
+
+	failureCodes, _ := ParseCodes(c.FailureCodes)
+
+	return interceptor.BreakerOptions{
+		Settings: gobreaker.Settings{
+			Name: c.Name,
+			ReadyToTrip: func(counts gobreaker.Counts) bool {
+				failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+
+				slog.Debug("Circuit breaker counts", "failures", counts.TotalFailures, "requests", counts.Requests, "failure_ratio", failureRatio)
+				return failureRatio > c.FailureRatio && counts.Requests >= c.MinRequests
+			},
+			Interval:    c.Interval.Std(),
+			Timeout:     c.Timeout.Std(),
+			MaxRequests: c.MaxRequests,
+			OnStateChange: func(name string, from, to gobreaker.State) {
+				slog.Warn("Circuit breaker changed state", "breaker", name, "from", from.String(), "to", to.String())
+			},
+		},
+		Key:          key,
+		FailureCodes: failureCodes,
+	}
+}
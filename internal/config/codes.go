@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+// ParseCode accepts gRPC code names in any case, with or without
+// underscores ("UNAVAILABLE", "InvalidArgument", "invalid_argument"), and
+// their numeric values.
+func ParseCode(s string) (codes.Code, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		if n > uint64(codes.Unauthenticated) {
+			return 0, fmt.Errorf("unknown status code %v", n)
+		}
+		return codes.Code(n), nil
+	}
+
+	normalized := strings.ToUpper(strings.ReplaceAll(s, "_", ""))
+	if normalized == "CANCELLED" {
+		return codes.Canceled, nil
+	}
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		if strings.ToUpper(c.String()) == normalized {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown status code %q", s)
+}
+
+func ParseCodes(names []string) ([]codes.Code, error) {
+	var result []codes.Code
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		c, err := ParseCode(name)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
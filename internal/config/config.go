@@ -0,0 +1,353 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/logging"
+	"github.com/VallabhSLEPAM/grpc-client/internal/tlsconfig"
+	"github.com/VallabhSLEPAM/grpc-client/internal/tracing"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the connection settings of the client. Values are resolved
+// from the defaults, then a YAML or JSON file, then GRPC_CLIENT_* environment
+// variables, then command line flags; a later source wins.
+type Config struct {
+	Server       ServerConfig      `yaml:"server" json:"server"`
+	TLS          TLSConfig         `yaml:"tls" json:"tls"`
+	Timeouts     TimeoutConfig     `yaml:"timeouts" json:"timeouts"`
+	Retry        RetryConfig       `yaml:"retry" json:"retry"`
+	Breaker      BreakerConfig     `yaml:"breaker" json:"breaker"`
+	Faults       FaultConfig       `yaml:"faults" json:"faults"`
+	Interceptors InterceptorConfig `yaml:"interceptors" json:"interceptors"`
+	Redaction    RedactionConfig   `yaml:"redaction" json:"redaction"`
+	Logging      LoggingConfig     `yaml:"logging" json:"logging"`
+	Metrics      MetricsConfig     `yaml:"metrics" json:"metrics"`
+	Tracing      TracingConfig     `yaml:"tracing" json:"tracing"`
+	Recording    RecordingConfig   `yaml:"recording" json:"recording"`
+}
+
+type ServerConfig struct {
+	Address string `yaml:"address" json:"address"`
+}
+
+type TLSConfig struct {
+	// Insecure disables transport security altogether.
+	Insecure bool   `yaml:"insecure" json:"insecure"`
+	CAFile   string `yaml:"caFile" json:"caFile"`
+
+	// CertFile and KeyFile hold the client certificate used for mutual TLS.
+	CertFile string `yaml:"certFile" json:"certFile"`
+	KeyFile  string `yaml:"keyFile" json:"keyFile"`
+
+	// ServerName overrides the name used to verify the server certificate.
+	ServerName string `yaml:"serverName" json:"serverName"`
+
+	// MinVersion is one of "1.0" to "1.3", empty means TLS 1.2.
+	MinVersion   string   `yaml:"minVersion" json:"minVersion"`
+	CipherSuites []string `yaml:"cipherSuites" json:"cipherSuites"`
+
+	// ReloadInterval is how often the certificate files are checked for
+	// changes. Zero loads them once at startup.
+	ReloadInterval Duration `yaml:"reloadInterval" json:"reloadInterval"`
+}
+
+// TimeoutConfig bounds calls unless the caller set an earlier deadline.
+// Zero disables the timeout.
+type TimeoutConfig struct {
+	Unary  Duration `yaml:"unary" json:"unary"`
+	Stream Duration `yaml:"stream" json:"stream"`
+
+	// Methods overrides Unary or Stream per full method name, e.g.
+	// "/bank.BankService/SummarizeTransactions".
+	Methods map[string]Duration `yaml:"methods" json:"methods"`
+}
+
+// UnaryOptions and StreamOptions convert the settings for the interceptors.
+func (c TimeoutConfig) UnaryOptions() interceptor.TimeoutOptions {
+	return c.options(c.Unary)
+}
+
+func (c TimeoutConfig) StreamOptions() interceptor.TimeoutOptions {
+	return c.options(c.Stream)
+}
+
+func (c TimeoutConfig) options(def Duration) interceptor.TimeoutOptions {
+	opts := interceptor.TimeoutOptions{
+		Default: def.Std(),
+		Methods: make(map[string]time.Duration, len(c.Methods)),
+	}
+	for method, timeout := range c.Methods {
+		opts.Methods[method] = timeout.Std()
+	}
+	return opts
+}
+
+// Enabled reports whether any call gets a timeout.
+func (c TimeoutConfig) Enabled() bool {
+	return c.Unary > 0 || c.Stream > 0 || len(c.Methods) > 0
+}
+
+type LoggingConfig struct {
+	// Level is "debug", "info", "warn" or "error".
+	Level string `yaml:"level" json:"level"`
+
+	// Format is "text" or "json".
+	Format string `yaml:"format" json:"format"`
+
+	// Output is "stderr", "stdout" or a file path. Files are rotated once
+	// they reach MaxSizeMB, keeping MaxBackups old files.
+	Output     string `yaml:"output" json:"output"`
+	MaxSizeMB  int    `yaml:"maxSizeMB" json:"maxSizeMB"`
+	MaxBackups int    `yaml:"maxBackups" json:"maxBackups"`
+}
+
+// LoggingOptions converts the settings for the logging package.
+func (c LoggingConfig) LoggingOptions() logging.Options {
+	return logging.Options{
+		Level:      c.Level,
+		Format:     c.Format,
+		Output:     c.Output,
+		MaxSize:    int64(c.MaxSizeMB) << 20,
+		MaxBackups: c.MaxBackups,
+	}
+}
+
+// MetricsConfig enables the Prometheus interceptors and the /metrics
+// endpoint when Address is set, e.g. ":9464".
+type MetricsConfig struct {
+	Address string `yaml:"address" json:"address"`
+
+	// Linger keeps the endpoint up after the command finished so the final
+	// values can be scraped.
+	Linger Duration `yaml:"linger" json:"linger"`
+}
+
+// TracingConfig configures the OpenTelemetry exporter. The tracing
+// interceptors always pass the trace context of the caller on, spans are
+// only recorded unless Exporter is "none".
+type TracingConfig struct {
+	// Exporter is "none", "stdout" or "otlp".
+	Exporter string `yaml:"exporter" json:"exporter"`
+
+	// Endpoint and Insecure configure the OTLP gRPC exporter, the
+	// OTEL_EXPORTER_OTLP_* variables apply when Endpoint is empty.
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+	Insecure bool   `yaml:"insecure" json:"insecure"`
+
+	ServiceName string  `yaml:"serviceName" json:"serviceName"`
+	SampleRatio float64 `yaml:"sampleRatio" json:"sampleRatio"`
+}
+
+const (
+	RecordingModeRecord = "record"
+	RecordingModeReplay = "replay"
+)
+
+// RecordingConfig records every call to File, or answers them from File
+// without a server, depending on Mode. An empty Mode does neither.
+type RecordingConfig struct {
+	Mode string `yaml:"mode" json:"mode"`
+	File string `yaml:"file" json:"file"`
+
+	// Strict fails replayed calls whose requests differ from the recording.
+	Strict bool `yaml:"strict" json:"strict"`
+}
+
+func (c RecordingConfig) validate() error {
+	switch c.Mode {
+	case "":
+		return nil
+	case RecordingModeRecord, RecordingModeReplay:
+		if c.File == "" {
+			return fmt.Errorf("recording.file is required in %v mode", c.Mode)
+		}
+		return nil
+	default:
+		return fmt.Errorf("recording.mode %q must be %v or %v", c.Mode, RecordingModeRecord, RecordingModeReplay)
+	}
+}
+
+func (c TracingConfig) TracingOptions() tracing.Options {
+	return tracing.Options{
+		Exporter:    c.Exporter,
+		Endpoint:    c.Endpoint,
+		Insecure:    c.Insecure,
+		ServiceName: c.ServiceName,
+		SampleRatio: c.SampleRatio,
+	}
+}
+
+type InterceptorConfig struct {
+	Logging  bool `yaml:"logging" json:"logging"`
+	Metadata bool `yaml:"metadata" json:"metadata"`
+
+	// Correlation sends a correlation ID, a request ID and the client time,
+	// version, OS and host with every call, and captures the response
+	// metadata of the calls.
+	Correlation bool `yaml:"correlation" json:"correlation"`
+}
+
+// Default returns the settings the client used before it was configurable.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Address: "localhost:9090",
+		},
+		TLS: TLSConfig{
+			CAFile: "ssl/ca.crt",
+		},
+		Retry: RetryConfig{
+			RetryPolicyConfig: RetryPolicyConfig{
+				Codes:       []string{"UNKNOWN", "INTERNAL"},
+				MaxAttempts: 4,
+				Backoff:     BackoffExponential,
+				BaseDelay:   Duration(2 * time.Second),
+				MaxDelay:    Duration(30 * time.Second),
+				Jitter:      0.2,
+			},
+		},
+		Breaker: BreakerConfig{
+			Name:         "my-circuit-breaker",
+			Key:          BreakerKeyMethod,
+			MaxRequests:  3,
+			Timeout:      Duration(4 * time.Second),
+			FailureRatio: 0.6,
+			MinRequests:  3,
+		},
+		Interceptors: InterceptorConfig{
+			Correlation: true,
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			ServiceName: "grpc-client",
+			SampleRatio: 1,
+		},
+		Logging: LoggingConfig{
+			Level:      "info",
+			Format:     logging.FormatText,
+			Output:     logging.OutputStderr,
+			MaxSizeMB:  100,
+			MaxBackups: 3,
+		},
+	}
+}
+
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Server.Address == "" {
+		errs = append(errs, errors.New("server.address is required"))
+	}
+	if !c.TLS.Insecure && c.TLS.CAFile == "" {
+		errs = append(errs, errors.New("tls.caFile is required unless tls.insecure is set"))
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("tls.certFile and tls.keyFile must be set together"))
+	}
+	if _, err := tlsconfig.ParseVersion(c.TLS.MinVersion); err != nil {
+		errs = append(errs, fmt.Errorf("tls.minVersion: %w", err))
+	}
+	if _, err := tlsconfig.ParseCipherSuites(c.TLS.CipherSuites); err != nil {
+		errs = append(errs, fmt.Errorf("tls.cipherSuites: %w", err))
+	}
+	if c.TLS.ReloadInterval < 0 {
+		errs = append(errs, errors.New("tls.reloadInterval must not be negative"))
+	}
+	if c.Timeouts.Unary < 0 {
+		errs = append(errs, errors.New("timeouts.unary must not be negative"))
+	}
+	if c.Timeouts.Stream < 0 {
+		errs = append(errs, errors.New("timeouts.stream must not be negative"))
+	}
+	for method, timeout := range c.Timeouts.Methods {
+		if timeout < 0 {
+			errs = append(errs, fmt.Errorf("timeouts.methods[%v] must not be negative", method))
+		}
+	}
+	if err := c.Retry.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Breaker.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Faults.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := c.Redaction.Redactor(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Metrics.Linger < 0 {
+		errs = append(errs, errors.New("metrics.linger must not be negative"))
+	}
+	if err := c.Tracing.TracingOptions().Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("tracing: %w", err))
+	}
+	if err := c.Logging.LoggingOptions().Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("logging: %w", err))
+	}
+	if err := c.Recording.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// TLSOptions converts the TLS settings for tlsconfig. The config is expected
+// to be valid.
+func (c TLSConfig) TLSOptions() tlsconfig.Options {
+	minVersion, _ := tlsconfig.ParseVersion(c.MinVersion)
+	cipherSuites, _ := tlsconfig.ParseCipherSuites(c.CipherSuites)
+	return tlsconfig.Options{
+		CAFile:       c.CAFile,
+		CertFile:     c.CertFile,
+		KeyFile:      c.KeyFile,
+		ServerName:   c.ServerName,
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+	}
+}
+
+// Duration is a time.Duration read from strings such as "5s" or "1m30s".
+type Duration time.Duration
+
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d *Duration) Set(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"5s\": %w", err)
+	}
+	return d.Set(s)
+}
+
+func (d Duration) MarshalYAML() (any, error) {
+	return d.String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	return d.Set(value.Value)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"google.golang.org/grpc/codes"
+)
+
+// FaultConfig configures the fault injection interceptors. The inline rule
+// is the default, Methods overrides it per full method name.
+type FaultConfig struct {
+	Enabled         bool `yaml:"enabled" json:"enabled"`
+	FaultRuleConfig `yaml:",inline"`
+
+	Methods map[string]FaultRuleConfig `yaml:"methods" json:"methods"`
+}
+
+type FaultRuleConfig struct {
+	Delay            Duration `yaml:"delay" json:"delay"`
+	DelayProbability float64  `yaml:"delayProbability" json:"delayProbability"`
+
+	// Code of the injected failures, UNAVAILABLE when empty.
+	Code string `yaml:"code" json:"code"`
+
+	AbortProbability float64 `yaml:"abortProbability" json:"abortProbability"`
+	DropProbability  float64 `yaml:"dropProbability" json:"dropProbability"`
+	ResetProbability float64 `yaml:"resetProbability" json:"resetProbability"`
+	ResetAfter       int     `yaml:"resetAfter" json:"resetAfter"`
+}
+
+func (c FaultRuleConfig) validate(prefix string) error {
+	var errs []error
+	if c.Code != "" {
+		if code, err := ParseCode(c.Code); err != nil {
+			errs = append(errs, fmt.Errorf("%v.code: %w", prefix, err))
+		} else if code == codes.OK {
+			errs = append(errs, fmt.Errorf("%v.code must not be OK", prefix))
+		}
+	}
+	if c.Delay < 0 || c.ResetAfter < 0 {
+		errs = append(errs, fmt.Errorf("%v.delay and resetAfter must not be negative", prefix))
+	}
+	probabilities := []struct {
+		name  string
+		value float64
+	}{
+		{"delayProbability", c.DelayProbability},
+		{"abortProbability", c.AbortProbability},
+		{"dropProbability", c.DropProbability},
+		{"resetProbability", c.ResetProbability},
+	}
+	for _, p := range probabilities {
+		if p.value < 0 || p.value > 1 {
+			errs = append(errs, fmt.Errorf("%v.%v %v must be in [0, 1]", prefix, p.name, p.value))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c FaultConfig) validate() error {
+	errs := []error{c.FaultRuleConfig.validate("faults")}
+	for method, rule := range c.Methods {
+		errs = append(errs, rule.validate(fmt.Sprintf("faults.methods[%v]", method)))
+	}
+	return errors.Join(errs...)
+}
+
+func (c FaultRuleConfig) rule() interceptor.FaultRule {
+	var code codes.Code
+	if c.Code != "" {
+		code, _ = ParseCode(c.Code)
+	}
+	return interceptor.FaultRule{
+		Delay:            c.Delay.Std(),
+		DelayProbability: c.DelayProbability,
+		Code:             code,
+		AbortProbability: c.AbortProbability,
+		DropProbability:  c.DropProbability,
+		ResetProbability: c.ResetProbability,
+		ResetAfter:       c.ResetAfter,
+	}
+}
+
+// FaultOptions converts the settings for the interceptors. The config is
+// expected to be valid.
+func (c FaultConfig) FaultOptions() interceptor.FaultOptions {
+	opts := interceptor.FaultOptions{
+		Default: c.FaultRuleConfig.rule(),
+		Methods: make(map[string]interceptor.FaultRule, len(c.Methods)),
+	}
+	for method, rule := range c.Methods {
+		opts.Methods[method] = rule.rule()
+	}
+	return opts
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EnvPrefix = "GRPC_CLIENT_"
+
+	// EnvConfigFile names the config file when none is given explicitly.
+	EnvConfigFile = EnvPrefix + "CONFIG"
+)
+
+// Load resolves the configuration from the defaults, the file at path (or
+// the one named by GRPC_CLIENT_CONFIG when path is empty) and the
+// environment. lookupEnv is usually os.LookupEnv.
+func Load(path string, lookupEnv func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path, _ = lookupEnv(EnvConfigFile)
+	}
+	if path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if err := applyEnv(&cfg, lookupEnv); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(cfg)
+	default:
+		return fmt.Errorf("config file %v: unsupported extension, use .yaml, .yml or .json", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config file %v: %w", path, err)
+	}
+	return nil
+}
+
+// envVars maps each supported variable, without the prefix, to the field it
+// sets.
+var envVars = map[string]func(cfg *Config, v string) error{
+	"SERVER_ADDRESS":           func(cfg *Config, v string) error { cfg.Server.Address = v; return nil },
+	"TLS_INSECURE":             func(cfg *Config, v string) error { return setBool(&cfg.TLS.Insecure, v) },
+	"TLS_CA_FILE":              func(cfg *Config, v string) error { cfg.TLS.CAFile = v; return nil },
+	"TLS_CERT_FILE":            func(cfg *Config, v string) error { cfg.TLS.CertFile = v; return nil },
+	"TLS_KEY_FILE":             func(cfg *Config, v string) error { cfg.TLS.KeyFile = v; return nil },
+	"TLS_SERVER_NAME":          func(cfg *Config, v string) error { cfg.TLS.ServerName = v; return nil },
+	"TLS_MIN_VERSION":          func(cfg *Config, v string) error { cfg.TLS.MinVersion = v; return nil },
+	"TLS_RELOAD_INTERVAL":      func(cfg *Config, v string) error { return cfg.TLS.ReloadInterval.Set(v) },
+	"TLS_CIPHER_SUITES":        func(cfg *Config, v string) error { cfg.TLS.CipherSuites = strings.Split(v, ","); return nil },
+	"TIMEOUT_UNARY":            func(cfg *Config, v string) error { return cfg.Timeouts.Unary.Set(v) },
+	"TIMEOUT_STREAM":           func(cfg *Config, v string) error { return cfg.Timeouts.Stream.Set(v) },
+	"RETRY_ENABLED":            func(cfg *Config, v string) error { return setBool(&cfg.Retry.Enabled, v) },
+	"RETRY_CODES":              func(cfg *Config, v string) error { cfg.Retry.Codes = strings.Split(v, ","); return nil },
+	"RETRY_MAX_ATTEMPTS":       func(cfg *Config, v string) error { return setInt(&cfg.Retry.MaxAttempts, v) },
+	"RETRY_BACKOFF":            func(cfg *Config, v string) error { cfg.Retry.Backoff = v; return nil },
+	"RETRY_BASE_DELAY":         func(cfg *Config, v string) error { return cfg.Retry.BaseDelay.Set(v) },
+	"RETRY_MAX_DELAY":          func(cfg *Config, v string) error { return cfg.Retry.MaxDelay.Set(v) },
+	"RETRY_JITTER":             func(cfg *Config, v string) error { return setFloat(&cfg.Retry.Jitter, v) },
+	"BREAKER_ENABLED":          func(cfg *Config, v string) error { return setBool(&cfg.Breaker.Enabled, v) },
+	"BREAKER_NAME":             func(cfg *Config, v string) error { cfg.Breaker.Name = v; return nil },
+	"BREAKER_KEY":              func(cfg *Config, v string) error { cfg.Breaker.Key = v; return nil },
+	"BREAKER_FAILURE_CODES":    func(cfg *Config, v string) error { cfg.Breaker.FailureCodes = strings.Split(v, ","); return nil },
+	"BREAKER_MAX_REQUESTS":     func(cfg *Config, v string) error { return setUint32(&cfg.Breaker.MaxRequests, v) },
+	"BREAKER_INTERVAL":         func(cfg *Config, v string) error { return cfg.Breaker.Interval.Set(v) },
+	"BREAKER_TIMEOUT":          func(cfg *Config, v string) error { return cfg.Breaker.Timeout.Set(v) },
+	"BREAKER_FAILURE_RATIO":    func(cfg *Config, v string) error { return setFloat(&cfg.Breaker.FailureRatio, v) },
+	"BREAKER_MIN_REQUESTS":     func(cfg *Config, v string) error { return setUint32(&cfg.Breaker.MinRequests, v) },
+	"INTERCEPTORS_LOGGING":     func(cfg *Config, v string) error { return setBool(&cfg.Interceptors.Logging, v) },
+	"INTERCEPTORS_METADATA":    func(cfg *Config, v string) error { return setBool(&cfg.Interceptors.Metadata, v) },
+	"INTERCEPTORS_CORRELATION": func(cfg *Config, v string) error { return setBool(&cfg.Interceptors.Correlation, v) },
+	"METRICS_ADDRESS":          func(cfg *Config, v string) error { cfg.Metrics.Address = v; return nil },
+	"METRICS_LINGER":           func(cfg *Config, v string) error { return cfg.Metrics.Linger.Set(v) },
+	"TRACING_EXPORTER":         func(cfg *Config, v string) error { cfg.Tracing.Exporter = v; return nil },
+	"TRACING_ENDPOINT":         func(cfg *Config, v string) error { cfg.Tracing.Endpoint = v; return nil },
+	"TRACING_INSECURE":         func(cfg *Config, v string) error { return setBool(&cfg.Tracing.Insecure, v) },
+	"TRACING_SERVICE_NAME":     func(cfg *Config, v string) error { cfg.Tracing.ServiceName = v; return nil },
+	"TRACING_SAMPLE_RATIO":     func(cfg *Config, v string) error { return setFloat(&cfg.Tracing.SampleRatio, v) },
+	"LOG_LEVEL":                func(cfg *Config, v string) error { cfg.Logging.Level = v; return nil },
+	"LOG_FORMAT":               func(cfg *Config, v string) error { cfg.Logging.Format = v; return nil },
+	"LOG_OUTPUT":               func(cfg *Config, v string) error { cfg.Logging.Output = v; return nil },
+	"LOG_MAX_SIZE_MB":          func(cfg *Config, v string) error { return setInt(&cfg.Logging.MaxSizeMB, v) },
+	"LOG_MAX_BACKUPS":          func(cfg *Config, v string) error { return setInt(&cfg.Logging.MaxBackups, v) },
+	"FAULT_ENABLED":            func(cfg *Config, v string) error { return setBool(&cfg.Faults.Enabled, v) },
+	"FAULT_DELAY":              func(cfg *Config, v string) error { return cfg.Faults.Delay.Set(v) },
+	"FAULT_DELAY_PROBABILITY":  func(cfg *Config, v string) error { return setFloat(&cfg.Faults.DelayProbability, v) },
+	"FAULT_CODE":               func(cfg *Config, v string) error { cfg.Faults.Code = v; return nil },
+	"FAULT_ABORT_PROBABILITY":  func(cfg *Config, v string) error { return setFloat(&cfg.Faults.AbortProbability, v) },
+	"FAULT_DROP_PROBABILITY":   func(cfg *Config, v string) error { return setFloat(&cfg.Faults.DropProbability, v) },
+	"FAULT_RESET_PROBABILITY":  func(cfg *Config, v string) error { return setFloat(&cfg.Faults.ResetProbability, v) },
+	"FAULT_RESET_AFTER":        func(cfg *Config, v string) error { return setInt(&cfg.Faults.ResetAfter, v) },
+	"RECORDING_MODE":           func(cfg *Config, v string) error { cfg.Recording.Mode = v; return nil },
+	"RECORDING_FILE":           func(cfg *Config, v string) error { cfg.Recording.File = v; return nil },
+	"RECORDING_STRICT":         func(cfg *Config, v string) error { return setBool(&cfg.Recording.Strict, v) },
+}
+
+func applyEnv(cfg *Config, lookupEnv func(string) (string, bool)) error {
+	for name, set := range envVars {
+		v, ok := lookupEnv(EnvPrefix + name)
+		if !ok {
+			continue
+		}
+		if err := set(cfg, v); err != nil {
+			return fmt.Errorf("%v%v: %w", EnvPrefix, name, err)
+		}
+	}
+	return nil
+}
+
+func setBool(dst *bool, v string) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	*dst = b
+	return nil
+}
+
+func setInt(dst *int, v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return err
+	}
+	*dst = n
+	return nil
+}
+
+func setUint32(dst *uint32, v string) error {
+	n, err := strconv.ParseUint(v, 10, 32)
+	if err != nil {
+		return err
+	}
+	*dst = uint32(n)
+	return nil
+}
+
+func setFloat(dst *float64, v string) error {
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return err
+	}
+	*dst = f
+	return nil
+}
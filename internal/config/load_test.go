@@ -0,0 +1,199 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Default().Validate() = %v", err)
+	}
+	if cfg.TLS.CertFile != "" || cfg.TLS.KeyFile != "" {
+		t.Errorf("Default() sets a client certificate %q/%q", cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := writeFile(t, "client.yaml", `
+server:
+  address: file:9090
+timeouts:
+  unary: 3s
+retry:
+  maxAttempts: 2
+`)
+	jsonFile := writeFile(t, "client.json", `{"server": {"address": "file:9090"}, "timeouts": {"unary": "3s"}, "retry": {"maxAttempts": 2}}`)
+
+	for _, path := range []string{yamlFile, jsonFile} {
+		t.Run(filepath.Ext(path), func(t *testing.T) {
+			cfg, err := Load(path, env(map[string]string{
+				EnvPrefix + "SERVER_ADDRESS":           "env:9090",
+				EnvPrefix + "INTERCEPTORS_CORRELATION": "false",
+			}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Server.Address != "env:9090" {
+				t.Errorf("Server.Address = %q, want the environment value", cfg.Server.Address)
+			}
+			if cfg.Timeouts.Unary.Std() != 3*time.Second {
+				t.Errorf("Timeouts.Unary = %v, want the file value", cfg.Timeouts.Unary)
+			}
+			if cfg.Retry.MaxAttempts != 2 {
+				t.Errorf("Retry.MaxAttempts = %v, want the file value", cfg.Retry.MaxAttempts)
+			}
+			if cfg.Retry.Backoff != BackoffExponential || cfg.Breaker.Name != Default().Breaker.Name {
+				t.Errorf("settings missing from the file lost their defaults: %+v %+v", cfg.Retry, cfg.Breaker)
+			}
+			if cfg.Interceptors.Correlation {
+				t.Error("Interceptors.Correlation = true, want the environment value")
+			}
+		})
+	}
+}
+
+func TestLoadFileFromEnv(t *testing.T) {
+	path := writeFile(t, "client.yml", "server:\n  address: file:9090\n")
+
+	cfg, err := Load("", env(map[string]string{EnvConfigFile: path}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Address != "file:9090" {
+		t.Errorf("Server.Address = %q, want the value from %v", cfg.Server.Address, EnvConfigFile)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		env  map[string]string
+		want string
+	}{
+		{name: "missing file", path: filepath.Join(t.TempDir(), "none.yaml"), want: "reading config file"},
+		{name: "unknown extension", path: writeFile(t, "client.toml", ""), want: "unsupported extension"},
+		{name: "unknown yaml field", path: writeFile(t, "client.yaml", "server:\n  port: 1\n"), want: "parsing config file"},
+		{name: "unknown json field", path: writeFile(t, "client.json", `{"server": {"port": 1}}`), want: "parsing config file"},
+		{name: "bad env value", env: map[string]string{EnvPrefix + "RETRY_MAX_ATTEMPTS": "many"}, want: EnvPrefix + "RETRY_MAX_ATTEMPTS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.path, env(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		want   []string
+	}{
+		{
+			name:   "no address",
+			modify: func(cfg *Config) { cfg.Server.Address = "" },
+			want:   []string{"server.address"},
+		},
+		{
+			name:   "no CA",
+			modify: func(cfg *Config) { cfg.TLS.CAFile = "" },
+			want:   []string{"tls.caFile"},
+		},
+		{
+			name:   "insecure without CA",
+			modify: func(cfg *Config) { cfg.TLS.CAFile, cfg.TLS.Insecure = "", true },
+		},
+		{
+			name:   "cert without key",
+			modify: func(cfg *Config) { cfg.TLS.CertFile = "client.crt" },
+			want:   []string{"tls.certFile and tls.keyFile"},
+		},
+		{
+			name:   "TLS version",
+			modify: func(cfg *Config) { cfg.TLS.MinVersion = "0.9" },
+			want:   []string{"tls.minVersion"},
+		},
+		{
+			name:   "negative timeouts",
+			modify: func(cfg *Config) { cfg.Timeouts.Unary = -1; cfg.Timeouts.Methods = map[string]Duration{"/a/B": -1} },
+			want:   []string{"timeouts.unary", "timeouts.methods[/a/B]"},
+		},
+		{
+			name:   "retry",
+			modify: func(cfg *Config) { cfg.Retry.Codes = []string{"NOPE"}; cfg.Retry.Jitter = 2 },
+			want:   []string{"retry.codes", "retry.jitter"},
+		},
+		{
+			name: "retry per method",
+			modify: func(cfg *Config) {
+				cfg.Retry.Methods = map[string]RetryPolicyConfig{"/a/B": {Backoff: "random"}}
+			},
+			want: []string{"retry.methods[/a/B].backoff"},
+		},
+		{
+			name:   "logging",
+			modify: func(cfg *Config) { cfg.Logging.Format = "xml" },
+			want:   []string{"logging"},
+		},
+		{
+			name:   "tracing",
+			modify: func(cfg *Config) { cfg.Tracing.Exporter = "zipkin" },
+			want:   []string{"tracing"},
+		},
+		{
+			name: "every error is reported",
+			modify: func(cfg *Config) {
+				cfg.Server.Address = ""
+				cfg.TLS.KeyFile = "client.key"
+				cfg.Metrics.Linger = -1
+			},
+			want: []string{"server.address", "tls.certFile and tls.keyFile", "metrics.linger"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want errors about %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %v, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
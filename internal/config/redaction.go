@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+)
+
+// RedactionConfig adds rules to redact.DefaultRules, or replaces them when
+// ReplaceDefaults is set.
+type RedactionConfig struct {
+	ReplaceDefaults bool                  `yaml:"replaceDefaults" json:"replaceDefaults"`
+	Rules           []RedactionRuleConfig `yaml:"rules" json:"rules"`
+}
+
+// RedactionRuleConfig mirrors redact.Rule, see there for the meaning of the
+// fields.
+type RedactionRuleConfig struct {
+	Field   string `yaml:"field" json:"field"`
+	Pattern string `yaml:"pattern" json:"pattern"`
+	Luhn    bool   `yaml:"luhn" json:"luhn"`
+	Action  string `yaml:"action" json:"action"`
+}
+
+// Redactor compiles the rules.
+func (c RedactionConfig) Redactor() (*redact.Redactor, error) {
+	var rules []redact.Rule
+	if !c.ReplaceDefaults {
+		rules = append(rules, redact.DefaultRules...)
+	}
+	for _, rule := range c.Rules {
+		rules = append(rules, redact.Rule{
+			Field:   rule.Field,
+			Pattern: rule.Pattern,
+			Luhn:    rule.Luhn,
+			Action:  rule.Action,
+		})
+	}
+	r, err := redact.New(rules...)
+	if err != nil {
+		return nil, fmt.Errorf("redaction: %w", err)
+	}
+	return r, nil
+}
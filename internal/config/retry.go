@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+)
+
+const (
+	BackoffExponential = "exponential"
+	BackoffLinear      = "linear"
+)
+
+// RetryConfig configures the retry interceptors. The inline policy is the
+// default, Methods overrides it per full method name.
+type RetryConfig struct {
+	Enabled           bool `yaml:"enabled" json:"enabled"`
+	RetryPolicyConfig `yaml:",inline"`
+
+	Methods map[string]RetryPolicyConfig `yaml:"methods" json:"methods"`
+}
+
+type RetryPolicyConfig struct {
+	Codes       []string `yaml:"codes" json:"codes"`
+	MaxAttempts int      `yaml:"maxAttempts" json:"maxAttempts"`
+
+	// Backoff is "exponential" or "linear". BaseDelay is the first delay for
+	// exponential and the step for linear backoff.
+	Backoff   string   `yaml:"backoff" json:"backoff"`
+	BaseDelay Duration `yaml:"baseDelay" json:"baseDelay"`
+	MaxDelay  Duration `yaml:"maxDelay" json:"maxDelay"`
+	Jitter    float64  `yaml:"jitter" json:"jitter"`
+
+	PerAttemptTimeout  Duration `yaml:"perAttemptTimeout" json:"perAttemptTimeout"`
+	AllowNonIdempotent bool     `yaml:"allowNonIdempotent" json:"allowNonIdempotent"`
+}
+
+func (c RetryPolicyConfig) validate(prefix string) error {
+	var errs []error
+	if _, err := ParseCodes(c.Codes); err != nil {
+		errs = append(errs, fmt.Errorf("%v.codes: %w", prefix, err))
+	}
+	if c.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("%v.maxAttempts must not be negative", prefix))
+	}
+	if c.Backoff != BackoffExponential && c.Backoff != BackoffLinear {
+		errs = append(errs, fmt.Errorf("%v.backoff %q must be %q or %q", prefix, c.Backoff, BackoffExponential, BackoffLinear))
+	}
+	if c.BaseDelay < 0 || c.MaxDelay < 0 || c.PerAttemptTimeout < 0 {
+		errs = append(errs, fmt.Errorf("%v delays and timeouts must not be negative", prefix))
+	}
+	if c.Jitter < 0 || c.Jitter > 1 {
+		errs = append(errs, fmt.Errorf("%v.jitter %v must be in [0, 1]", prefix, c.Jitter))
+	}
+	return errors.Join(errs...)
+}
+
+func (c RetryConfig) validate() error {
+	errs := []error{c.RetryPolicyConfig.validate("retry")}
+	for method, policy := range c.Methods {
+		errs = append(errs, policy.validate(fmt.Sprintf("retry.methods[%v]", method)))
+	}
+	return errors.Join(errs...)
+}
+
+func (c RetryPolicyConfig) policy() interceptor.RetryPolicy {
+	codes, _ := ParseCodes(c.Codes)
+
+	backoff := interceptor.BackoffExponential(c.BaseDelay.Std(), c.MaxDelay.Std(), c.Jitter)
+	if c.Backoff == BackoffLinear {
+		backoff = interceptor.BackoffLinear(c.BaseDelay.Std(), c.Jitter)
+	}
+
+	return interceptor.RetryPolicy{
+		Codes:              codes,
+		MaxAttempts:        c.MaxAttempts,
+		Backoff:            backoff,
+		PerAttemptTimeout:  c.PerAttemptTimeout.Std(),
+		AllowNonIdempotent: c.AllowNonIdempotent,
+	}
+}
+
+// RetryOptions converts the settings for the interceptors. The config is
+// expected to be valid.
+func (c RetryConfig) RetryOptions() interceptor.RetryOptions {
+	opts := interceptor.RetryOptions{
+		Default: c.RetryPolicyConfig.policy(),
+		Methods: make(map[string]interceptor.RetryPolicy, len(c.Methods)),
+	}
+	for method, policy := range c.Methods {
+		opts.Methods[method] = policy.policy()
+	}
+	return opts
+}
@@ -0,0 +1,117 @@
+// Package correlation builds the metadata that ties a call to the client and
+// to the request it was made for.
+package correlation
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys sent along with calls.
+const (
+	IDHeader            = "x-correlation-id"
+	RequestIDHeader     = "grpc-request-uuid"
+	ClientTimeHeader    = "grpc-client-time"
+	ClientOSHeader      = "grpc-client-os"
+	ClientVersionHeader = "grpc-client-version"
+	ClientHostHeader    = "grpc-client-host"
+)
+
+type idKey struct{}
+
+// WithID makes calls made with ctx send id instead of a new one.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// ID returns the ID set by WithID or, when the client is used from within a
+// gRPC server, the one of the incoming request.
+func ID(ctx context.Context) (string, bool) {
+	if id, ok := ctx.Value(idKey{}).(string); ok && id != "" {
+		return id, true
+	}
+	if ids := metadata.ValueFromIncomingContext(ctx, IDHeader); len(ids) > 0 && ids[0] != "" {
+		return ids[0], true
+	}
+	return "", false
+}
+
+// Outgoing adds the correlation ID, a request ID unique to the call, the
+// client time and OS, then the key value pairs of kv, to the outgoing
+// metadata of ctx. Keys already there are kept, so it can run more than once
+// for a call. The correlation ID reuses the one of ID(ctx) if any, and is
+// stored in the returned context too.
+func Outgoing(ctx context.Context, kv ...string) (context.Context, string) {
+	out, _ := metadata.FromOutgoingContext(ctx)
+	id, _ := ID(ctx)
+	if ids := out.Get(IDHeader); len(ids) > 0 {
+		id = ids[0]
+	} else if id == "" {
+		id = uuid.NewString()
+	}
+
+	pairs := append([]string{
+		IDHeader, id,
+		RequestIDHeader, uuid.NewString(),
+		ClientTimeHeader, time.Now().Format(time.RFC3339),
+		ClientOSHeader, runtime.GOOS,
+	}, kv...)
+	var missing []string
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if len(out.Get(pairs[i])) == 0 {
+			missing = append(missing, pairs[i], pairs[i+1])
+		}
+	}
+	if len(missing) > 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, missing...)
+	}
+	return WithID(ctx, id), id
+}
+
+// Metadata is what the server sent alongside the messages of a call.
+type Metadata struct {
+	ID      string
+	Header  metadata.MD
+	Trailer metadata.MD
+}
+
+// Capture holds the Metadata of the last call made with the context
+// returned by WithCapture. It is filled by the correlation interceptors.
+type Capture struct {
+	mu sync.Mutex
+	md Metadata
+}
+
+type captureKey struct{}
+
+// WithCapture returns a context whose calls record their correlation ID and
+// response metadata into the returned Capture once they ended.
+func WithCapture(ctx context.Context) (context.Context, *Capture) {
+	c := &Capture{}
+	return context.WithValue(ctx, captureKey{}, c), c
+}
+
+// CaptureFrom returns the Capture of ctx, if any.
+func CaptureFrom(ctx context.Context) (*Capture, bool) {
+	c, ok := ctx.Value(captureKey{}).(*Capture)
+	return c, ok
+}
+
+// Record replaces the captured metadata with the one of a call that ended.
+func (c *Capture) Record(id string, header, trailer metadata.MD) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.md = Metadata{ID: id, Header: header, Trailer: trailer}
+}
+
+// Metadata returns the metadata of the last call that ended.
+func (c *Capture) Metadata() Metadata {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.md
+}
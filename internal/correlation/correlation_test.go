@@ -0,0 +1,119 @@
+package correlation
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+func outgoing(t *testing.T, ctx context.Context) metadata.MD {
+	t.Helper()
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		t.Fatal("no outgoing metadata")
+	}
+	return md
+}
+
+func TestOutgoingGeneratesIDs(t *testing.T) {
+	ctx, id := Outgoing(context.Background())
+	md := outgoing(t, ctx)
+
+	if _, err := uuid.Parse(id); err != nil {
+		t.Errorf("correlation ID %q is not a UUID: %v", id, err)
+	}
+	if got := md.Get(IDHeader); len(got) != 1 || got[0] != id {
+		t.Errorf("%v = %q, want %q", IDHeader, got, id)
+	}
+	if stored, ok := ID(ctx); !ok || stored != id {
+		t.Errorf("ID(ctx) = %q, want %q", stored, id)
+	}
+	if got := md.Get(RequestIDHeader); len(got) != 1 || got[0] == id {
+		t.Errorf("%v = %q, want its own ID", RequestIDHeader, got)
+	}
+	if got := md.Get(ClientTimeHeader); len(got) != 1 {
+		t.Errorf("%v = %q", ClientTimeHeader, got)
+	} else if _, err := time.Parse(time.RFC3339, got[0]); err != nil {
+		t.Errorf("%v is not RFC 3339: %v", ClientTimeHeader, err)
+	}
+	if got := md.Get(ClientOSHeader); len(got) != 1 || got[0] != runtime.GOOS {
+		t.Errorf("%v = %q", ClientOSHeader, got)
+	}
+
+	_, other := Outgoing(context.Background())
+	if other == id {
+		t.Error("two calls without a correlation ID share one")
+	}
+}
+
+func TestOutgoingReusesID(t *testing.T) {
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs(IDHeader, "from-server"))
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "WithID", ctx: WithID(context.Background(), "set"), want: "set"},
+		{name: "incoming", ctx: incoming, want: "from-server"},
+		{name: "WithID over incoming", ctx: WithID(incoming, "set"), want: "set"},
+		{name: "outgoing header", ctx: metadata.AppendToOutgoingContext(WithID(incoming, "set"), IDHeader, "header"), want: "header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, id := Outgoing(tt.ctx)
+			if id != tt.want {
+				t.Errorf("ID = %q, want %q", id, tt.want)
+			}
+			if got := outgoing(t, ctx).Get(IDHeader); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("%v = %q, want %q once", IDHeader, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutgoingKeepsHeadersSet(t *testing.T) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		RequestIDHeader, "mine",
+		ClientVersionHeader, "1.0")
+
+	ctx, _ = Outgoing(ctx, ClientVersionHeader, "2.0", ClientHostHeader, "host")
+	// Running again for the same call, as a retried attempt does, adds nothing.
+	ctx, _ = Outgoing(ctx)
+	md := outgoing(t, ctx)
+
+	want := map[string]string{
+		RequestIDHeader:     "mine",
+		ClientVersionHeader: "1.0",
+		ClientHostHeader:    "host",
+	}
+	for key, value := range want {
+		if got := md.Get(key); len(got) != 1 || got[0] != value {
+			t.Errorf("%v = %q, want %q", key, got, value)
+		}
+	}
+	if got := md.Get(IDHeader); len(got) != 1 {
+		t.Errorf("%v = %q, want a single ID", IDHeader, got)
+	}
+}
+
+func TestCapture(t *testing.T) {
+	if _, ok := CaptureFrom(context.Background()); ok {
+		t.Error("CaptureFrom found a capture in an empty context")
+	}
+
+	ctx, c := WithCapture(context.Background())
+	if got, ok := CaptureFrom(ctx); !ok || got != c {
+		t.Fatal("CaptureFrom did not return the capture of WithCapture")
+	}
+	c.Record("first", metadata.Pairs("a", "1"), nil)
+	c.Record("second", nil, metadata.Pairs("b", "2"))
+
+	md := c.Metadata()
+	if md.ID != "second" || md.Header != nil || md.Trailer.Get("b")[0] != "2" {
+		t.Errorf("Metadata() = %+v, want the last call", md)
+	}
+}
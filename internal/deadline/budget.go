@@ -0,0 +1,79 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted is returned by Budget.Next when too little time is left
+// to start another call.
+var ErrBudgetExhausted = errors.New("deadline budget exhausted")
+
+// Budget splits the time left before the deadline of a parent context
+// between a known number of sequential calls, e.g. a bank call followed by
+// a resiliency call. Each call gets an equal share of what is left when it
+// starts, so time a call did not use carries over to the next ones.
+type Budget struct {
+	deadline    time.Time
+	hasDeadline bool
+
+	// reserve is kept back for local work after the last call.
+	reserve time.Duration
+
+	now func() time.Time
+
+	mu    sync.Mutex
+	calls int
+	next  int
+}
+
+// New creates a budget for calls calls from the deadline of ctx. Without a
+// deadline on ctx the calls are not bounded by the budget.
+func New(ctx context.Context, calls int, reserve time.Duration) *Budget {
+	deadline, ok := ctx.Deadline()
+	return &Budget{
+		deadline:    deadline,
+		hasDeadline: ok,
+		reserve:     reserve,
+		now:         time.Now,
+		calls:       max(calls, 1),
+	}
+}
+
+// Remaining returns the time left for calls, the reserve excluded. It is
+// negative once the budget is exhausted, and ok is false without a deadline.
+func (b *Budget) Remaining() (remaining time.Duration, ok bool) {
+	if !b.hasDeadline {
+		return 0, false
+	}
+	return b.deadline.Sub(b.now()) - b.reserve, true
+}
+
+// Next returns the context for the next call and its cancel function. It
+// fails with ErrBudgetExhausted, without touching the network, when the
+// budget is used up or when more calls were made than planned.
+func (b *Budget) Next(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.next >= b.calls {
+		return nil, nil, fmt.Errorf("%w: all %v planned calls were made", ErrBudgetExhausted, b.calls)
+	}
+	b.next++
+
+	if !b.hasDeadline {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+
+	remaining, _ := b.Remaining()
+	if remaining <= 0 {
+		return nil, nil, fmt.Errorf("%w: no time left for call %v of %v with %v reserved", ErrBudgetExhausted, b.next, b.calls, b.reserve)
+	}
+	share := remaining / time.Duration(b.calls-b.next+1)
+	ctx, cancel := context.WithDeadline(ctx, b.now().Add(share))
+	return ctx, cancel, nil
+}
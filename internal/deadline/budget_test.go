@@ -0,0 +1,144 @@
+package deadline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudgetNext(t *testing.T) {
+	type step struct {
+		// elapsed passes before the call starts.
+		elapsed time.Duration
+		// share is the timeout the call gets, zero for no deadline.
+		share   time.Duration
+		wantErr bool
+	}
+	tests := []struct {
+		name    string
+		timeout time.Duration // zero for a parent without deadline
+		calls   int
+		reserve time.Duration
+		steps   []step
+	}{
+		{
+			name:    "even split",
+			timeout: 900 * time.Millisecond,
+			calls:   3,
+			steps: []step{
+				{elapsed: 0, share: 300 * time.Millisecond},
+				{elapsed: 300 * time.Millisecond, share: 300 * time.Millisecond},
+				{elapsed: 300 * time.Millisecond, share: 300 * time.Millisecond},
+			},
+		},
+		{
+			name:    "unused time carries over",
+			timeout: 900 * time.Millisecond,
+			calls:   3,
+			steps: []step{
+				{elapsed: 0, share: 300 * time.Millisecond},
+				{elapsed: 100 * time.Millisecond, share: 400 * time.Millisecond},
+				{elapsed: 100 * time.Millisecond, share: 700 * time.Millisecond},
+			},
+		},
+		{
+			name:    "reserve is kept back",
+			timeout: time.Second,
+			calls:   3,
+			reserve: 100 * time.Millisecond,
+			steps: []step{
+				{elapsed: 0, share: 300 * time.Millisecond},
+				{elapsed: 300 * time.Millisecond, share: 300 * time.Millisecond},
+				{elapsed: 300 * time.Millisecond, share: 300 * time.Millisecond},
+			},
+		},
+		{
+			name:    "exhausted before the last call",
+			timeout: 500 * time.Millisecond,
+			calls:   2,
+			reserve: 100 * time.Millisecond,
+			steps: []step{
+				{elapsed: 0, share: 200 * time.Millisecond},
+				{elapsed: 400 * time.Millisecond, wantErr: true},
+			},
+		},
+		{
+			name:    "more calls than planned",
+			timeout: time.Second,
+			calls:   2,
+			steps: []step{
+				{elapsed: 0, share: 500 * time.Millisecond},
+				{elapsed: 0, share: time.Second},
+				{elapsed: 0, wantErr: true},
+			},
+		},
+		{
+			name:  "no deadline",
+			calls: 2,
+			steps: []step{
+				{elapsed: 0},
+				{elapsed: time.Hour},
+				{elapsed: 0, wantErr: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			parent := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				parent, cancel = context.WithDeadline(parent, now.Add(tt.timeout))
+				defer cancel()
+			}
+			b := New(parent, tt.calls, tt.reserve)
+			b.now = func() time.Time { return now }
+
+			for i, step := range tt.steps {
+				now = now.Add(step.elapsed)
+				ctx, cancel, err := b.Next(parent)
+				if step.wantErr {
+					if !errors.Is(err, ErrBudgetExhausted) {
+						t.Fatalf("call %v: err = %v, want ErrBudgetExhausted", i+1, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("call %v: %v", i+1, err)
+				}
+				defer cancel()
+
+				deadline, ok := ctx.Deadline()
+				switch {
+				case step.share == 0 && ok:
+					t.Errorf("call %v: got deadline in %v, want none", i+1, deadline.Sub(now))
+				case step.share != 0 && !ok:
+					t.Errorf("call %v: got no deadline, want %v", i+1, step.share)
+				case step.share != 0 && deadline.Sub(now) != step.share:
+					t.Errorf("call %v: got share %v, want %v", i+1, deadline.Sub(now), step.share)
+				}
+			}
+		})
+	}
+}
+
+func TestBudgetRemaining(t *testing.T) {
+	if _, ok := New(context.Background(), 1, 0).Remaining(); ok {
+		t.Error("Remaining without a deadline reported ok")
+	}
+
+	now := time.Now()
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Second))
+	defer cancel()
+	b := New(ctx, 1, 200*time.Millisecond)
+	b.now = func() time.Time { return now }
+	if got, ok := b.Remaining(); !ok || got != 800*time.Millisecond {
+		t.Errorf("Remaining = %v, %v, want 800ms, true", got, ok)
+	}
+
+	now = now.Add(2 * time.Second)
+	if got, ok := b.Remaining(); !ok || got >= 0 {
+		t.Errorf("Remaining past the deadline = %v, %v, want negative, true", got, ok)
+	}
+}
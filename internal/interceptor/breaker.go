@@ -0,0 +1,341 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BreakerKey picks the breaker a call goes through.
+type BreakerKey func(cc *grpc.ClientConn, method string) string
+
+// BreakerPerMethod keeps one breaker per full method name.
+func BreakerPerMethod(cc *grpc.ClientConn, method string) string {
+	return method
+}
+
+// BreakerPerService shares a breaker between the methods of a service.
+func BreakerPerService(cc *grpc.ClientConn, method string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return service
+}
+
+// BreakerPerTarget shares a breaker between all calls to the same server.
+func BreakerPerTarget(cc *grpc.ClientConn, method string) string {
+	if cc == nil {
+		return ""
+	}
+	return cc.Target()
+}
+
+// DefaultBreakerFailureCodes are the codes that point at an unhealthy
+// server. Client side mistakes such as InvalidArgument or NotFound do not
+// count against the breaker.
+var DefaultBreakerFailureCodes = []codes.Code{
+	codes.Unknown,
+	codes.DeadlineExceeded,
+	codes.ResourceExhausted,
+	codes.Internal,
+	codes.Unavailable,
+	codes.DataLoss,
+}
+
+type BreakerOptions struct {
+	// Settings is the template of every breaker. The key is appended to
+	// Name to name each breaker.
+	Settings gobreaker.Settings
+
+	// Key defaults to BreakerPerMethod.
+	Key BreakerKey
+
+	// FailureCodes defaults to DefaultBreakerFailureCodes.
+	FailureCodes []codes.Code
+}
+
+// BreakerStatus is a snapshot of one breaker.
+type BreakerStatus struct {
+	Name string
+	Key  string
+
+	// State is the forced state while Forced is set.
+	State  gobreaker.State
+	Forced bool
+	Counts gobreaker.Counts
+
+	// LastTransition is when the state last changed, or when the breaker was
+	// created if it never did.
+	LastTransition time.Time
+
+	// Trips counts the transitions to open, forcing aside.
+	Trips int
+}
+
+// BreakerOpenError is returned for the calls an open breaker rejects. It
+// reaches the caller as a codes.Unavailable status.
+type BreakerOpenError struct {
+	Breaker string
+	Forced  bool
+
+	// Err is the gobreaker error, unset when Forced.
+	Err error
+}
+
+func (e *BreakerOpenError) Error() string {
+	if e.Forced {
+		return fmt.Sprintf("%v: circuit breaker is forced open", e.Breaker)
+	}
+	return fmt.Sprintf("%v: %v", e.Breaker, e.Err)
+}
+
+func (e *BreakerOpenError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+func (e *BreakerOpenError) Unwrap() error {
+	return e.Err
+}
+
+// BreakerRegistry lazily creates one breaker per key.
+type BreakerRegistry struct {
+	opts BreakerOptions
+
+	mu       sync.Mutex
+	breakers map[string]*breakerEntry
+}
+
+type breakerEntry struct {
+	key string
+	cb  *gobreaker.TwoStepCircuitBreaker
+
+	mu             sync.Mutex
+	forced         *gobreaker.State
+	lastTransition time.Time
+	trips          int
+}
+
+func (e *breakerEntry) transitioned(to gobreaker.State) {
+	e.mu.Lock()
+	e.lastTransition = time.Now()
+	if to == gobreaker.StateOpen {
+		e.trips++
+	}
+	e.mu.Unlock()
+}
+
+func (e *breakerEntry) forcedState() (gobreaker.State, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.forced == nil {
+		return 0, false
+	}
+	return *e.forced, true
+}
+
+func NewBreakerRegistry(opts BreakerOptions) *BreakerRegistry {
+	if opts.Key == nil {
+		opts.Key = BreakerPerMethod
+	}
+	if opts.FailureCodes == nil {
+		opts.FailureCodes = DefaultBreakerFailureCodes
+	}
+	return &BreakerRegistry{
+		opts:     opts,
+		breakers: make(map[string]*breakerEntry),
+	}
+}
+
+func (r *BreakerRegistry) breaker(key string) *breakerEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.breakers[key]
+	if !ok {
+		entry = &breakerEntry{key: key, lastTransition: time.Now()}
+
+		settings := r.opts.Settings
+		settings.Name = breakerName(settings.Name, key)
+		onStateChange := settings.OnStateChange
+		settings.OnStateChange = func(name string, from, to gobreaker.State) {
+			entry.transitioned(to)
+			if onStateChange != nil {
+				onStateChange(name, from, to)
+			}
+		}
+		entry.cb = gobreaker.NewTwoStepCircuitBreaker(settings)
+		r.breakers[key] = entry
+	}
+	return entry
+}
+
+// Breakers returns the status of every breaker created so far, sorted by
+// key.
+func (r *BreakerRegistry) Breakers() []BreakerStatus {
+	r.mu.Lock()
+	entries := make([]*breakerEntry, 0, len(r.breakers))
+	for _, entry := range r.breakers {
+		entries = append(entries, entry)
+	}
+	r.mu.Unlock()
+
+	result := make([]BreakerStatus, 0, len(entries))
+	for _, entry := range entries {
+		st := BreakerStatus{
+			Name:   entry.cb.Name(),
+			Key:    entry.key,
+			State:  entry.cb.State(),
+			Counts: entry.cb.Counts(),
+		}
+		if forced, ok := entry.forcedState(); ok {
+			st.State = forced
+			st.Forced = true
+		}
+		entry.mu.Lock()
+		st.LastTransition = entry.lastTransition
+		st.Trips = entry.trips
+		entry.mu.Unlock()
+		result = append(result, st)
+	}
+	slices.SortFunc(result, func(a, b BreakerStatus) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return result
+}
+
+// ForceOpen rejects every call of the breaker for key until Unforce is
+// called. The breaker is created if needed.
+func (r *BreakerRegistry) ForceOpen(key string) {
+	r.force(key, gobreaker.StateOpen)
+}
+
+// ForceClosed lets every call of the breaker for key through, without
+// counting its outcome, until Unforce is called.
+func (r *BreakerRegistry) ForceClosed(key string) {
+	r.force(key, gobreaker.StateClosed)
+}
+
+// Unforce hands the breaker for key back to its trip rules.
+func (r *BreakerRegistry) Unforce(key string) {
+	entry := r.breaker(key)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.forced != nil {
+		entry.forced = nil
+		entry.lastTransition = time.Now()
+	}
+}
+
+func (r *BreakerRegistry) force(key string, state gobreaker.State) {
+	entry := r.breaker(key)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	entry.forced = &state
+	entry.lastTransition = time.Now()
+	slog.Warn("Circuit breaker forced", "breaker", entry.cb.Name(), "state", state.String())
+}
+
+func breakerName(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + " " + key
+}
+
+// IsFailure reports whether err counts as a failure for the breaker.
+func (r *BreakerRegistry) IsFailure(err error) bool {
+	if err == nil || errors.Is(err, io.EOF) {
+		return false
+	}
+	return slices.Contains(r.opts.FailureCodes, status.Code(err))
+}
+
+// allow asks the breaker of the call for permission. When it is open the
+// returned error is a *BreakerOpenError.
+func (r *BreakerRegistry) allow(cc *grpc.ClientConn, method string) (func(error), error) {
+	entry := r.breaker(r.opts.Key(cc, method))
+	switch forced, ok := entry.forcedState(); {
+	case ok && forced == gobreaker.StateOpen:
+		return nil, &BreakerOpenError{Breaker: entry.cb.Name(), Forced: true}
+	case ok:
+		return func(error) {}, nil
+	}
+
+	done, err := entry.cb.Allow()
+	if err != nil {
+		return nil, &BreakerOpenError{Breaker: entry.cb.Name(), Err: err}
+	}
+	return func(callErr error) {
+		done(!r.IsFailure(callErr))
+	}, nil
+}
+
+func BreakerUnaryClientInterceptor(registry *BreakerRegistry) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		done, err := registry.allow(cc, method)
+		if err != nil {
+			return err
+		}
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		done(err)
+		return err
+	}
+}
+
+// BreakerStreamClientInterceptor reports the outcome of a stream once it
+// ends, that is when RecvMsg returns an error, io.EOF or the single response
+// of a client streaming call, or when its context ends first. gRPC expects
+// callers that stop reading early to cancel the context, which is then
+// reported as a Canceled call.
+func BreakerStreamClientInterceptor(registry *BreakerRegistry) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		done, err := registry.allow(cc, method)
+		if err != nil {
+			return nil, err
+		}
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			done(err)
+			return nil, err
+		}
+		s := &breakerClientStream{ClientStream: stream, serverStreams: desc.ServerStreams, done: done}
+		s.stop = context.AfterFunc(ctx, func() {
+			s.finish(status.FromContextError(ctx.Err()).Err())
+		})
+		return s, nil
+	}
+}
+
+type breakerClientStream struct {
+	grpc.ClientStream
+
+	// Without server streaming the single response ends the call.
+	serverStreams bool
+
+	// stop unregisters the report on context end.
+	stop func() bool
+	once sync.Once
+	done func(error)
+}
+
+func (s *breakerClientStream) finish(err error) {
+	s.once.Do(func() { s.done(err) })
+}
+
+func (s *breakerClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || !s.serverStreams {
+		s.stop()
+		s.finish(err)
+	}
+	return err
+}
@@ -0,0 +1,220 @@
+package interceptor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"github.com/sony/gobreaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const breakerTimeout = 50 * time.Millisecond
+
+// newBreakers trips after two consecutive failures and lets one call
+// through once half-open.
+func newBreakers(key interceptor.BreakerKey, failureCodes ...codes.Code) *interceptor.BreakerRegistry {
+	return interceptor.NewBreakerRegistry(interceptor.BreakerOptions{
+		Settings: gobreaker.Settings{
+			Name:        "test",
+			MaxRequests: 1,
+			Timeout:     breakerTimeout,
+			ReadyToTrip: func(counts gobreaker.Counts) bool { return counts.ConsecutiveFailures >= 2 },
+		},
+		Key:          key,
+		FailureCodes: failureCodes,
+	})
+}
+
+func dialBreakers(t *testing.T, registry *interceptor.BreakerRegistry) (*fakeserver.Server, hello.HelloServiceClient) {
+	t.Helper()
+	srv, conn := dialFake(t,
+		grpc.WithChainUnaryInterceptor(interceptor.BreakerUnaryClientInterceptor(registry)),
+		grpc.WithChainStreamInterceptor(interceptor.BreakerStreamClientInterceptor(registry)))
+	return srv, hello.NewHelloServiceClient(conn)
+}
+
+// waitState polls until the only breaker reaches want.
+func waitState(t *testing.T, registry *interceptor.BreakerRegistry, want gobreaker.State) interceptor.BreakerStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		breakers := registry.Breakers()
+		if len(breakers) != 1 {
+			t.Fatalf("Breakers() = %+v, want one breaker", breakers)
+		}
+		if breakers[0].State == want {
+			return breakers[0]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("breaker state = %v, want %v", breakers[0].State, want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestBreakerKeys(t *testing.T) {
+	_, conn := dialFake(t)
+	tests := []struct {
+		name string
+		key  interceptor.BreakerKey
+		cc   *grpc.ClientConn
+		want string
+	}{
+		{name: "method", key: interceptor.BreakerPerMethod, want: sayHello},
+		{name: "service", key: interceptor.BreakerPerService, want: "hello.HelloService"},
+		{name: "target", key: interceptor.BreakerPerTarget, cc: conn, want: conn.Target()},
+		{name: "target without conn", key: interceptor.BreakerPerTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key(tt.cc, sayHello); got != tt.want {
+				t.Errorf("key = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBreakerTripsAndRecovers(t *testing.T) {
+	registry := newBreakers(interceptor.BreakerPerMethod)
+	srv, client := dialBreakers(t, registry)
+	ctx := context.Background()
+
+	for range 2 {
+		srv.Hello.SayHello.Fail(fakeserver.Error(codes.Unavailable, "down"))
+		if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"}); status.Code(err) != codes.Unavailable {
+			t.Fatalf("SayHello() = %v, want the scripted failure", err)
+		}
+	}
+
+	_, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"})
+	var openErr *interceptor.BreakerOpenError
+	if !errors.As(err, &openErr) || status.Code(err) != codes.Unavailable {
+		t.Fatalf("SayHello() = %v, want a BreakerOpenError with code Unavailable", err)
+	}
+	if got := len(srv.Hello.SayHello.Calls()); got != 2 {
+		t.Errorf("calls = %v, the open breaker let a call through", got)
+	}
+	if st := waitState(t, registry, gobreaker.StateOpen); st.Key != sayHello || st.Trips != 1 {
+		t.Errorf("status = %+v, want one trip of the %v breaker", st, sayHello)
+	}
+
+	waitState(t, registry, gobreaker.StateHalfOpen)
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatalf("SayHello() half-open = %v", err)
+	}
+	waitState(t, registry, gobreaker.StateClosed)
+}
+
+func TestBreakerHalfOpenFailureReopens(t *testing.T) {
+	registry := newBreakers(interceptor.BreakerPerMethod)
+	srv, client := dialBreakers(t, registry)
+	ctx := context.Background()
+	for range 3 {
+		srv.Hello.SayHello.Fail(fakeserver.Error(codes.Internal, "broken"))
+	}
+	for range 2 {
+		client.SayHello(ctx, &hello.HelloRequest{Name: "a"})
+	}
+
+	waitState(t, registry, gobreaker.StateHalfOpen)
+	client.SayHello(ctx, &hello.HelloRequest{Name: "a"})
+	if st := waitState(t, registry, gobreaker.StateOpen); st.Trips != 2 {
+		t.Errorf("trips = %v, want 2", st.Trips)
+	}
+}
+
+func TestBreakerReleasesAbandonedStreams(t *testing.T) {
+	registry := newBreakers(interceptor.BreakerPerMethod)
+	srv, client := dialBreakers(t, registry)
+	for range 2 {
+		srv.Hello.HelloServerStream.Fail(fakeserver.Error(codes.Unavailable, "down"))
+		stream, err := client.HelloServerStream(context.Background(), &hello.HelloRequest{Name: "a"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream.Recv()
+	}
+	waitState(t, registry, gobreaker.StateHalfOpen)
+
+	// The only half-open slot goes to a stream left after one message.
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.HelloServerStream(ctx, &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	waitState(t, registry, gobreaker.StateClosed)
+	if _, err := client.HelloServerStream(context.Background(), &hello.HelloRequest{Name: "b"}); err != nil {
+		t.Errorf("HelloServerStream() after the abandoned stream = %v", err)
+	}
+}
+
+func TestBreakerFailureCodes(t *testing.T) {
+	registry := newBreakers(interceptor.BreakerPerMethod, codes.NotFound)
+	if !registry.IsFailure(status.Error(codes.NotFound, "gone")) {
+		t.Error("NotFound is not a failure with custom codes")
+	}
+	if registry.IsFailure(status.Error(codes.Unavailable, "down")) {
+		t.Error("Unavailable is a failure although only NotFound was configured")
+	}
+
+	srv, client := dialBreakers(t, registry)
+	ctx := context.Background()
+	for range 3 {
+		srv.Hello.SayHello.Fail(fakeserver.Error(codes.Unavailable, "down"))
+		client.SayHello(ctx, &hello.HelloRequest{Name: "a"})
+	}
+	waitState(t, registry, gobreaker.StateClosed)
+
+	for range 2 {
+		srv.Hello.SayHello.Fail(fakeserver.Error(codes.NotFound, "gone"))
+		client.SayHello(ctx, &hello.HelloRequest{Name: "a"})
+	}
+	waitState(t, registry, gobreaker.StateOpen)
+}
+
+func TestBreakerRegistry(t *testing.T) {
+	registry := newBreakers(interceptor.BreakerPerService)
+	srv, client := dialBreakers(t, registry)
+	ctx := context.Background()
+
+	registry.ForceOpen("hello.HelloService")
+	_, err := client.HelloServerStream(ctx, &hello.HelloRequest{Name: "a"})
+	var openErr *interceptor.BreakerOpenError
+	if !errors.As(err, &openErr) || !openErr.Forced {
+		t.Fatalf("HelloServerStream() = %v, want a forced BreakerOpenError", err)
+	}
+	if st := waitState(t, registry, gobreaker.StateOpen); !st.Forced || st.Trips != 0 {
+		t.Errorf("status = %+v, want forced open without trips", st)
+	}
+
+	registry.ForceClosed("hello.HelloService")
+	for range 3 {
+		srv.Hello.SayHello.Fail(fakeserver.Error(codes.Unavailable, "down"))
+		client.SayHello(ctx, &hello.HelloRequest{Name: "a"})
+	}
+	if st := waitState(t, registry, gobreaker.StateClosed); !st.Forced || st.Counts.Requests != 0 {
+		t.Errorf("status = %+v, want forced closed without counting calls", st)
+	}
+
+	registry.Unforce("hello.HelloService")
+	for range 2 {
+		srv.Hello.SayHello.Fail(fakeserver.Error(codes.Unavailable, "down"))
+		client.SayHello(ctx, &hello.HelloRequest{Name: "a"})
+	}
+	waitState(t, registry, gobreaker.StateOpen)
+	if _, err := client.HelloServerStream(ctx, &hello.HelloRequest{Name: "a"}); !errors.As(err, &openErr) {
+		t.Errorf("HelloServerStream() = %v, want the service breaker opened by SayHello", err)
+	}
+}
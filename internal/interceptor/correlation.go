@@ -0,0 +1,111 @@
+package interceptor
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/correlation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+type CorrelationOptions struct {
+	// Version is sent as grpc-client-version, which is left out when it is
+	// empty.
+	Version string
+
+	// Host defaults to os.Hostname().
+	Host string
+}
+
+func (o CorrelationOptions) withDefaults() CorrelationOptions {
+	if o.Host == "" {
+		o.Host, _ = os.Hostname()
+	}
+	return o
+}
+
+// outgoing adds the correlation headers to ctx. The correlation ID is also
+// stored in the returned context so later interceptors can log it.
+func (o CorrelationOptions) outgoing(ctx context.Context) (context.Context, string) {
+	var kv []string
+	if o.Version != "" {
+		kv = append(kv, correlation.ClientVersionHeader, o.Version)
+	}
+	if o.Host != "" {
+		kv = append(kv, correlation.ClientHostHeader, o.Host)
+	}
+	return correlation.Outgoing(ctx, kv...)
+}
+
+// CorrelationUnaryClientInterceptor sends a correlation ID, reusing the one
+// of ctx if any, a request ID unique to the call and the client time,
+// version, OS and host. Headers the caller already set are kept. The
+// response header and trailer are recorded into the correlation.Capture of
+// ctx, if any.
+func CorrelationUnaryClientInterceptor(opts CorrelationOptions) grpc.UnaryClientInterceptor {
+	opts = opts.withDefaults()
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		ctx, id := opts.outgoing(ctx)
+		capture, ok := correlation.CaptureFrom(ctx)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+
+		var header, trailer metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Header(&header), grpc.Trailer(&trailer))...)
+		capture.Record(id, header, trailer)
+		return err
+	}
+}
+
+// CorrelationStreamClientInterceptor is the stream counterpart of
+// CorrelationUnaryClientInterceptor. The response metadata is recorded once
+// the stream ends, that is when RecvMsg returns an error, io.EOF or the
+// single response of a client streaming call.
+func CorrelationStreamClientInterceptor(opts CorrelationOptions) grpc.StreamClientInterceptor {
+	opts = opts.withDefaults()
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, id := opts.outgoing(ctx)
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		capture, ok := correlation.CaptureFrom(ctx)
+		if !ok {
+			return stream, err
+		}
+		if err != nil {
+			capture.Record(id, nil, nil)
+			return nil, err
+		}
+		return &correlationClientStream{
+			ClientStream:  stream,
+			capture:       capture,
+			id:            id,
+			serverStreams: desc.ServerStreams,
+		}, nil
+	}
+}
+
+type correlationClientStream struct {
+	grpc.ClientStream
+
+	capture *correlation.Capture
+	id      string
+
+	// Without server streaming the single response ends the call.
+	serverStreams bool
+
+	once sync.Once
+}
+
+func (s *correlationClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil || !s.serverStreams {
+		s.once.Do(func() {
+			// Neither blocks once the stream ended
+			header, _ := s.ClientStream.Header()
+			s.capture.Record(s.id, header, s.ClientStream.Trailer())
+		})
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package interceptor_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/correlation"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func dialCorrelation(t *testing.T, opts interceptor.CorrelationOptions) (*fakeserver.Server, hello.HelloServiceClient) {
+	t.Helper()
+	srv, conn := dialFake(t,
+		grpc.WithChainUnaryInterceptor(interceptor.CorrelationUnaryClientInterceptor(opts)),
+		grpc.WithChainStreamInterceptor(interceptor.CorrelationStreamClientInterceptor(opts)))
+	return srv, hello.NewHelloServiceClient(conn)
+}
+
+func TestCorrelationHeaders(t *testing.T) {
+	srv, client := dialCorrelation(t, interceptor.CorrelationOptions{Version: "1.2.3", Host: "test-host"})
+
+	ctx := correlation.WithID(context.Background(), "caller-id")
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SayHello(context.Background(), &hello.HelloRequest{Name: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := srv.Hello.SayHello.Calls()
+	first, second := calls[0].Metadata, calls[1].Metadata
+	if got := first.Get(correlation.IDHeader); len(got) != 1 || got[0] != "caller-id" {
+		t.Errorf("%v = %q, want the ID of the caller", correlation.IDHeader, got)
+	}
+	if got := second.Get(correlation.IDHeader); len(got) != 1 || got[0] == "" || got[0] == "caller-id" {
+		t.Errorf("%v = %q, want a new ID", correlation.IDHeader, got)
+	}
+	if first.Get(correlation.RequestIDHeader)[0] == second.Get(correlation.RequestIDHeader)[0] {
+		t.Error("two calls share a request ID")
+	}
+	for key, want := range map[string]string{correlation.ClientVersionHeader: "1.2.3", correlation.ClientHostHeader: "test-host"} {
+		if got := first.Get(key); len(got) != 1 || got[0] != want {
+			t.Errorf("%v = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestCorrelationWithoutVersion(t *testing.T) {
+	srv, client := dialCorrelation(t, interceptor.CorrelationOptions{})
+	if _, err := client.SayHello(context.Background(), &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	md := srv.Hello.SayHello.Calls()[0].Metadata
+	if got, ok := md[correlation.ClientVersionHeader]; ok {
+		t.Errorf("%v = %q, want it left out", correlation.ClientVersionHeader, got)
+	}
+	if got := md.Get(correlation.ClientHostHeader); len(got) != 1 || got[0] == "" {
+		t.Errorf("%v = %q, want the host name", correlation.ClientHostHeader, got)
+	}
+}
+
+func TestCorrelationCapturesResponseMetadata(t *testing.T) {
+	srv, client := dialCorrelation(t, interceptor.CorrelationOptions{})
+	header, trailer := metadata.Pairs("server", "fake"), metadata.Pairs("cost", "3")
+
+	ctx, calls := correlation.WithCapture(correlation.WithID(context.Background(), "caller-id"))
+	srv.Hello.SayHello.Push(fakeserver.Step[hello.HelloResponse]{
+		Header:  header,
+		Trailer: trailer,
+		Err:     fakeserver.Error(codes.NotFound, "gone"),
+	})
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("SayHello() = %v", err)
+	}
+	md := calls.Metadata()
+	if md.ID != "caller-id" || md.Header.Get("server")[0] != "fake" || md.Trailer.Get("cost")[0] != "3" {
+		t.Errorf("unary call captured %+v", md)
+	}
+
+	srv.Hello.HelloServerStream.Push(fakeserver.Step[hello.HelloResponse]{
+		Header:    metadata.Pairs("server", "stream"),
+		Trailer:   metadata.Pairs("cost", "10"),
+		Responses: []*hello.HelloResponse{{Greet: "1"}},
+	})
+	stream, err := client.HelloServerStream(ctx, &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	md = calls.Metadata()
+	if md.Header.Get("server")[0] != "stream" || md.Trailer.Get("cost")[0] != "10" {
+		t.Errorf("stream captured %+v", md)
+	}
+
+	srv.Hello.HelloClientStream.Push(fakeserver.Step[hello.HelloResponse]{
+		Trailer:   metadata.Pairs("cost", "1"),
+		Responses: []*hello.HelloResponse{{Greet: "all"}},
+	})
+	clientStream, err := client.HelloClientStream(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := clientStream.Send(&hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientStream.CloseAndRecv(); err != nil {
+		t.Fatal(err)
+	}
+	if md = calls.Metadata(); md.Trailer.Get("cost")[0] != "1" {
+		t.Errorf("client stream captured %+v", md)
+	}
+}
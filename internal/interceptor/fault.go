@@ -0,0 +1,202 @@
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FaultRule describes the faults injected into the calls of a method. Each
+// probability is in [0, 1] and rolled on its own for every call.
+type FaultRule struct {
+	// Delay is waited before the call with DelayProbability.
+	Delay            time.Duration
+	DelayProbability float64
+
+	// Code is the status of the injected failures, codes.Unavailable when
+	// left to OK.
+	Code codes.Code
+
+	// AbortProbability fails the call before it reaches the server.
+	AbortProbability float64
+
+	// DropProbability discards a response after the server sent it. Unary
+	// and client streaming calls fail instead of returning their response,
+	// the other streams skip the message and go on with the next one.
+	DropProbability float64
+
+	// ResetProbability resets streams once ResetAfter messages were
+	// received.
+	ResetProbability float64
+	ResetAfter       int
+}
+
+func (r FaultRule) code() codes.Code {
+	if r.Code == codes.OK {
+		return codes.Unavailable
+	}
+	return r.Code
+}
+
+func (r FaultRule) streams() bool {
+	return r.DropProbability > 0 || r.ResetProbability > 0
+}
+
+type FaultOptions struct {
+	Default FaultRule
+
+	// Methods overrides Default per full method name.
+	Methods map[string]FaultRule
+
+	// Rand returns numbers in [0, 1), rand.Float64 when nil.
+	Rand func() float64
+}
+
+func (o FaultOptions) rule(method string) FaultRule {
+	if r, ok := o.Methods[method]; ok {
+		return r
+	}
+	return o.Default
+}
+
+func (o FaultOptions) roll(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	if o.Rand == nil {
+		return rand.Float64() < p
+	}
+	return o.Rand() < p
+}
+
+func faultError(method string, rule FaultRule, fault string) error {
+	slog.Debug("Fault injected", "method", method, "fault", fault, "code", rule.code().String())
+	return status.Errorf(rule.code(), "fault injection: %v", fault)
+}
+
+// before injects the delay and abort of rule.
+func (o FaultOptions) before(ctx context.Context, method string, rule FaultRule) error {
+	if o.roll(rule.DelayProbability) && rule.Delay > 0 {
+		slog.Debug("Fault injected", "method", method, "fault", "delay", "delay", rule.Delay)
+		t := time.NewTimer(rule.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if o.roll(rule.AbortProbability) {
+		return faultError(method, rule, "call aborted")
+	}
+	return nil
+}
+
+// FaultUnaryClientInterceptor injects latency, failures and dropped
+// responses to exercise retries and breakers against any service. It
+// belongs inside the retry and breaker interceptors so they see the faults.
+func FaultUnaryClientInterceptor(opts FaultOptions) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		rule := opts.rule(method)
+		if err := opts.before(ctx, method, rule); err != nil {
+			return err
+		}
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		if err == nil && opts.roll(rule.DropProbability) {
+			return faultError(method, rule, "response dropped")
+		}
+		return err
+	}
+}
+
+// FaultStreamClientInterceptor is the stream counterpart of
+// FaultUnaryClientInterceptor. Injected resets, and drops of the single
+// response, cancel the underlying stream.
+func FaultStreamClientInterceptor(opts FaultOptions) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		rule := opts.rule(method)
+		if err := opts.before(ctx, method, rule); err != nil {
+			return nil, err
+		}
+		if !rule.streams() {
+			return streamer(ctx, desc, cc, method, callOpts...)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		return &faultClientStream{
+			ClientStream:  stream,
+			opts:          opts,
+			rule:          rule,
+			method:        method,
+			reset:         opts.roll(rule.ResetProbability),
+			serverStreams: desc.ServerStreams,
+			cancel:        cancel,
+		}, nil
+	}
+}
+
+type faultClientStream struct {
+	grpc.ClientStream
+
+	opts   FaultOptions
+	rule   FaultRule
+	method string
+
+	// reset is rolled when the stream starts.
+	reset         bool
+	serverStreams bool
+	cancel        context.CancelFunc
+
+	mu       sync.Mutex
+	received int
+	err      error
+}
+
+// fail ends the stream with err, later calls to RecvMsg return it too.
+func (s *faultClientStream) fail(err error) error {
+	s.err = err
+	s.cancel()
+	return err
+}
+
+func (s *faultClientStream) RecvMsg(m any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	for {
+		if s.reset && s.received >= s.rule.ResetAfter {
+			return s.fail(faultError(s.method, s.rule, "stream reset"))
+		}
+
+		err := s.ClientStream.RecvMsg(m)
+		if err != nil {
+			s.cancel()
+			return err
+		}
+		s.received++
+		if !s.opts.roll(s.rule.DropProbability) {
+			break
+		}
+		if !s.serverStreams {
+			return s.fail(faultError(s.method, s.rule, "response dropped"))
+		}
+		slog.Debug("Fault injected", "method", s.method, "fault", "message dropped")
+	}
+	if !s.serverStreams {
+		s.cancel()
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package interceptor_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rolls returns the scripted numbers in turn, then 1 so no fault fires.
+func rolls(nums ...float64) func() float64 {
+	return func() float64 {
+		if len(nums) == 0 {
+			return 1
+		}
+		n := nums[0]
+		nums = nums[1:]
+		return n
+	}
+}
+
+func dialFault(t *testing.T, opts interceptor.FaultOptions) (*fakeserver.Server, hello.HelloServiceClient) {
+	t.Helper()
+	srv, conn := dialFake(t,
+		grpc.WithChainUnaryInterceptor(interceptor.FaultUnaryClientInterceptor(opts)),
+		grpc.WithChainStreamInterceptor(interceptor.FaultStreamClientInterceptor(opts)))
+	return srv, hello.NewHelloServiceClient(conn)
+}
+
+func TestFaultUnary(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      interceptor.FaultRule
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		{name: "no fault", rule: interceptor.FaultRule{AbortProbability: 0.5}, wantCode: codes.OK, wantCalls: 1},
+		{name: "abort", rule: interceptor.FaultRule{AbortProbability: 1}, wantCode: codes.Unavailable},
+		{name: "abort with code", rule: interceptor.FaultRule{AbortProbability: 1, Code: codes.Internal}, wantCode: codes.Internal},
+		{name: "drop", rule: interceptor.FaultRule{DropProbability: 1}, wantCode: codes.Unavailable, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := dialFault(t, interceptor.FaultOptions{Default: tt.rule, Rand: rolls(0.9)})
+			_, err := client.SayHello(context.Background(), &hello.HelloRequest{Name: "a"})
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("SayHello() = %v, want %v", err, tt.wantCode)
+			}
+			if got := len(srv.Hello.SayHello.Calls()); got != tt.wantCalls {
+				t.Errorf("server got %d calls, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestFaultPerMethod(t *testing.T) {
+	_, client := dialFault(t, interceptor.FaultOptions{
+		Default: interceptor.FaultRule{AbortProbability: 1},
+		Methods: map[string]interceptor.FaultRule{sayHello: {}},
+	})
+	if _, err := client.SayHello(context.Background(), &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Errorf("SayHello() = %v, want the method rule without faults", err)
+	}
+	if _, err := client.HelloServerStream(context.Background(), &hello.HelloRequest{Name: "a"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("HelloServerStream() = %v, want the default abort", err)
+	}
+}
+
+func TestFaultDelay(t *testing.T) {
+	_, client := dialFault(t, interceptor.FaultOptions{Default: interceptor.FaultRule{Delay: 50 * time.Millisecond, DelayProbability: 1}})
+	start := time.Now()
+	if _, err := client.SayHello(context.Background(), &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("call took %v, want the 50ms delay", elapsed)
+	}
+
+	_, client = dialFault(t, interceptor.FaultOptions{Default: interceptor.FaultRule{Delay: time.Hour, DelayProbability: 1}})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"}); status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("delayed SayHello() = %v, want DeadlineExceeded once the context ends", err)
+	}
+}
+
+func TestFaultStreamDropSkipsMessage(t *testing.T) {
+	// The second message is dropped, the others pass.
+	_, client := dialFault(t, interceptor.FaultOptions{
+		Default: interceptor.FaultRule{DropProbability: 0.5},
+		Rand:    rolls(0.9, 0.1),
+	})
+	stream, err := client.HelloServerStream(context.Background(), &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var greets []string
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() = %v after %d messages, want the stream to go on", err, len(greets))
+		}
+		greets = append(greets, resp.GetGreet())
+	}
+	if len(greets) != fakeserver.StreamLength-1 {
+		t.Fatalf("received %d messages, want %d", len(greets), fakeserver.StreamLength-1)
+	}
+	if greets[0] != "Hello a 1" || greets[1] != "Hello a 3" {
+		t.Errorf("received %q, want the second message skipped", greets)
+	}
+}
+
+func TestFaultClientStreamDrop(t *testing.T) {
+	_, client := dialFault(t, interceptor.FaultOptions{Default: interceptor.FaultRule{DropProbability: 1, Code: codes.Aborted}})
+	stream, err := client.HelloClientStream(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.Aborted {
+		t.Errorf("CloseAndRecv() = %v, want the dropped response to fail with Aborted", err)
+	}
+}
+
+func TestFaultStreamReset(t *testing.T) {
+	_, client := dialFault(t, interceptor.FaultOptions{Default: interceptor.FaultRule{ResetProbability: 1, ResetAfter: 3}})
+	stream, err := client.HelloServerStream(context.Background(), &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := 0
+	for {
+		_, err := stream.Recv()
+		if err != nil {
+			if status.Code(err) != codes.Unavailable {
+				t.Errorf("Recv() = %v, want the injected reset", err)
+			}
+			break
+		}
+		received++
+	}
+	if received != 3 {
+		t.Errorf("received %d messages before the reset, want 3", received)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Recv() after the reset = %v, want the reset again", err)
+	}
+}
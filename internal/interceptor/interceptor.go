@@ -2,24 +2,16 @@ package interceptor
 
 import (
 	"context"
-	"log"
-	"time"
+	"log/slog"
 
 	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
 	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/resiliency"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
-func LogUnaryClientInterceptor() grpc.UnaryClientInterceptor {
-
-	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		log.Println("[LOGGED BY UNARY CLIENT INTERCEPTOR]", req)
-		return invoker(ctx, method, req, reply, cc, opts...)
-	}
-}
-
 // Adding metadata to client request and modifying response
 func BasicUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
@@ -50,20 +42,6 @@ func BasicUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 	}
 }
 
-func UnaryTimeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
-	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		newCtx, _ := context.WithTimeout(ctx, timeout)
-		return invoker(newCtx, method, req, reply, cc, opts...)
-	}
-}
-
-func LogStreamClientInterceptor() grpc.StreamClientInterceptor {
-	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-		log.Println("[LOGGED BY CLIENT STREAM INTERCEPTOR]", method)
-		return streamer(ctx, desc, cc, method, opts...)
-	}
-}
-
 // Interceptor to modify the client metadata
 type InterceptedClientStream struct {
 	grpc.ClientStream
@@ -79,7 +57,7 @@ func BasicClientStreamInterceptor() grpc.StreamClientInterceptor {
 
 		clientStream, err := streamer(ctx, desc, cc, method, opts...)
 		if err != nil {
-			log.Printf("Failed to start %v streaming call to %v: %v", desc.StreamName, method, err)
+			slog.Warn("Failed to start streaming call", "stream", desc.StreamName, "method", method, "error", redact.Default().Error(err))
 			return nil, err
 		}
 
@@ -112,10 +90,3 @@ func (interceptedClientStream *InterceptedClientStream) Recv(msg interface{}) er
 	}
 	return nil
 }
-
-func TimeoutStreamClientIntereptor(timeout time.Duration) grpc.StreamClientInterceptor {
-	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-		newCtx, _ := context.WithTimeout(ctx, timeout)
-		return streamer(newCtx, desc, cc, method, opts...)
-	}
-}
@@ -0,0 +1,197 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/correlation"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type LoggingOptions struct {
+	// Logger defaults to slog.Default().
+	Logger *slog.Logger
+
+	// Redactor masks sensitive fields of the messages logged at debug level
+	// and of error messages. It defaults to redact.Default().
+	Redactor *redact.Redactor
+}
+
+func (o LoggingOptions) withDefaults() LoggingOptions {
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	if o.Redactor == nil {
+		o.Redactor = redact.Default()
+	}
+	return o
+}
+
+// LoggingUnaryClientInterceptor logs every call once it finished, with its
+// method, peer, duration, status code and message sizes. Successful calls
+// are logged at info level and failed ones at warn level; the redacted
+// request and response are added at debug level.
+func LoggingUnaryClientInterceptor(opts LoggingOptions) grpc.UnaryClientInterceptor {
+	opts = opts.withDefaults()
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		var p peer.Peer
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, append(callOpts, grpc.Peer(&p))...)
+
+		attrs := []slog.Attr{
+			slog.String("method", method),
+			slog.String("peer", peerAddr(&p)),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("code", status.Code(err).String()),
+			slog.Int("request_size", messageSize(req)),
+		}
+		if err == nil {
+			attrs = append(attrs, slog.Int("response_size", messageSize(reply)))
+		}
+		if opts.Logger.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs, slog.Any("request", opts.Redactor.Message(req)))
+			if err == nil {
+				attrs = append(attrs, slog.Any("response", opts.Redactor.Message(reply)))
+			}
+		}
+		logCall(ctx, opts, "unary call finished", err, attrs)
+		return err
+	}
+}
+
+// LoggingStreamClientInterceptor logs every stream once it ended, that is
+// when RecvMsg returns an error, io.EOF or the single response of a client
+// streaming call, with the message counts and total sizes in both
+// directions. Each message is logged at debug level.
+func LoggingStreamClientInterceptor(opts LoggingOptions) grpc.StreamClientInterceptor {
+	opts = opts.withDefaults()
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			logCall(ctx, opts, "stream failed to start", err, []slog.Attr{
+				slog.String("method", method),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("code", status.Code(err).String()),
+			})
+			return nil, err
+		}
+		return &loggingClientStream{
+			ClientStream:  stream,
+			opts:          opts,
+			ctx:           ctx,
+			method:        method,
+			start:         start,
+			serverStreams: desc.ServerStreams,
+		}, nil
+	}
+}
+
+type loggingClientStream struct {
+	grpc.ClientStream
+
+	opts          LoggingOptions
+	ctx           context.Context
+	method        string
+	start         time.Time
+	serverStreams bool
+
+	mu                 sync.Mutex
+	sent, received     int
+	sentSize, recvSize int
+	once               sync.Once
+}
+
+func (s *loggingClientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		s.sent++
+		s.sentSize += messageSize(m)
+		s.mu.Unlock()
+		s.debugMessage("stream message sent", m)
+	}
+	return err
+}
+
+func (s *loggingClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		s.received++
+		s.recvSize += messageSize(m)
+		s.mu.Unlock()
+		s.debugMessage("stream message received", m)
+	}
+	if err != nil || !s.serverStreams {
+		s.once.Do(func() { s.finish(err) })
+	}
+	return err
+}
+
+func (s *loggingClientStream) debugMessage(msg string, m any) {
+	if s.opts.Logger.Enabled(s.ctx, slog.LevelDebug) {
+		s.opts.Logger.LogAttrs(s.ctx, slog.LevelDebug, msg,
+			slog.String("method", s.method),
+			slog.Any("message", s.opts.Redactor.Message(m)))
+	}
+}
+
+func (s *loggingClientStream) finish(err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	var addr string
+	if p, ok := peer.FromContext(s.ClientStream.Context()); ok {
+		addr = peerAddr(p)
+	}
+
+	s.mu.Lock()
+	attrs := []slog.Attr{
+		slog.String("method", s.method),
+		slog.String("peer", addr),
+		slog.Duration("duration", time.Since(s.start)),
+		slog.String("code", status.Code(err).String()),
+		slog.Int("sent_messages", s.sent),
+		slog.Int("sent_size", s.sentSize),
+		slog.Int("received_messages", s.received),
+		slog.Int("received_size", s.recvSize),
+	}
+	s.mu.Unlock()
+	logCall(s.ctx, s.opts, "stream finished", err, attrs)
+}
+
+func logCall(ctx context.Context, opts LoggingOptions, msg string, err error, attrs []slog.Attr) {
+	level := slog.LevelInfo
+	if status.Code(err) != codes.OK {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", opts.Redactor.String(status.Convert(err).Message())))
+	}
+	if id, ok := correlation.ID(ctx); ok {
+		attrs = append(attrs, slog.String("correlation_id", id))
+	}
+	opts.Logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+func peerAddr(p *peer.Peer) string {
+	if p == nil || p.Addr == nil {
+		return ""
+	}
+	return p.Addr.String()
+}
+
+func messageSize(m any) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+	return 0
+}
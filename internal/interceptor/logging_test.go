@@ -0,0 +1,187 @@
+package interceptor_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// logBuffer collects JSON log records.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func dialLogging(t *testing.T, level slog.Level) (*fakeserver.Server, hello.HelloServiceClient, *logBuffer) {
+	t.Helper()
+	redactor, err := redact.New(redact.Rule{Field: "name"}, redact.Rule{Pattern: `secret-\w+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := &logBuffer{}
+	opts := interceptor.LoggingOptions{
+		Logger:   slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: level})),
+		Redactor: redactor,
+	}
+	srv, conn := dialFake(t,
+		grpc.WithChainUnaryInterceptor(interceptor.LoggingUnaryClientInterceptor(opts)),
+		grpc.WithChainStreamInterceptor(interceptor.LoggingStreamClientInterceptor(opts)))
+	return srv, hello.NewHelloServiceClient(conn), logs
+}
+
+func TestLoggingUnaryLevels(t *testing.T) {
+	srv, client, logs := dialLogging(t, slog.LevelInfo)
+	ctx := context.Background()
+
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	srv.Hello.SayHello.Fail(fakeserver.Error(codes.Unavailable, "lost secret-token"))
+	client.SayHello(ctx, &hello.HelloRequest{Name: "bob"})
+
+	records := logs.records(t)
+	if len(records) != 2 {
+		t.Fatalf("logged %v records, want 2: %v", len(records), records)
+	}
+	ok, failed := records[0], records[1]
+
+	if ok["level"] != "INFO" || ok["code"] != "OK" || ok["method"] != sayHello {
+		t.Errorf("success logged as %v", ok)
+	}
+	if _, found := ok["duration"]; !found {
+		t.Error("success logged without a duration")
+	}
+	if ok["request_size"] == float64(0) || ok["response_size"] == nil {
+		t.Errorf("success logged sizes %v and %v", ok["request_size"], ok["response_size"])
+	}
+	if _, found := ok["request"]; found {
+		t.Error("request logged above debug level")
+	}
+
+	if failed["level"] != "WARN" || failed["code"] != "Unavailable" {
+		t.Errorf("failure logged as %v", failed)
+	}
+	if failed["error"] != "lost "+redact.Mask {
+		t.Errorf("error = %q, want it redacted", failed["error"])
+	}
+	if _, found := failed["response_size"]; found {
+		t.Error("failure logged with a response size")
+	}
+}
+
+func TestLoggingUnaryDebugRedactsMessages(t *testing.T) {
+	_, client, logs := dialLogging(t, slog.LevelDebug)
+
+	if _, err := client.SayHello(context.Background(), &hello.HelloRequest{Name: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	records := logs.records(t)
+	if len(records) != 1 {
+		t.Fatalf("logged %v records, want 1", len(records))
+	}
+	request, _ := records[0]["request"].(map[string]any)
+	if request["name"] != redact.Mask {
+		t.Errorf("request = %v, want the name redacted", records[0]["request"])
+	}
+	response, _ := records[0]["response"].(map[string]any)
+	if response["greet"] != "Hello alice" {
+		t.Errorf("response = %v", records[0]["response"])
+	}
+	if strings.Contains(logs.buf.String(), `"alice"`) {
+		t.Errorf("the name leaked into the logs: %v", logs.buf.String())
+	}
+}
+
+func TestLoggingStream(t *testing.T) {
+	srv, client, logs := dialLogging(t, slog.LevelDebug)
+
+	stream, err := client.HelloServerStream(context.Background(), &hello.HelloRequest{Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records := logs.records(t)
+	var sent, received int
+	var finished map[string]any
+	for _, r := range records {
+		switch r["msg"] {
+		case "stream message sent":
+			sent++
+			if message, _ := r["message"].(map[string]any); message["name"] != redact.Mask {
+				t.Errorf("sent message = %v, want the name redacted", r["message"])
+			}
+		case "stream message received":
+			received++
+		case "stream finished":
+			finished = r
+		}
+	}
+	if sent != 1 || received != fakeserver.StreamLength {
+		t.Errorf("logged %v sent and %v received messages", sent, received)
+	}
+	if finished == nil {
+		t.Fatal("stream end not logged")
+	}
+	if finished["level"] != "INFO" || finished["code"] != "OK" ||
+		finished["sent_messages"] != float64(1) || finished["received_messages"] != float64(fakeserver.StreamLength) {
+		t.Errorf("stream logged as %v", finished)
+	}
+	if _, found := finished["duration"]; !found {
+		t.Error("stream logged without a duration")
+	}
+
+	srv.Hello.HelloServerStream.Fail(fakeserver.Error(codes.Internal, "broken"))
+	stream, err = client.HelloServerStream(context.Background(), &hello.HelloRequest{Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Recv()
+	records = logs.records(t)
+	if last := records[len(records)-1]; last["level"] != "WARN" || last["code"] != "Internal" || last["error"] != "broken" {
+		t.Errorf("failed stream logged as %v", last)
+	}
+}
@@ -0,0 +1,187 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sony/gobreaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics holds the Prometheus collectors updated by the metrics
+// interceptors.
+type Metrics struct {
+	requests         *prometheus.CounterVec
+	duration         *prometheus.HistogramVec
+	inFlight         *prometheus.GaugeVec
+	messagesSent     *prometheus.CounterVec
+	messagesReceived *prometheus.CounterVec
+}
+
+// NewMetrics creates the collectors and registers them with reg, usually
+// prometheus.DefaultRegisterer or a prometheus.NewRegistry() in tests.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_handled_total",
+			Help: "Completed RPCs by method and status code.",
+		}, []string{"method", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "grpc_client_handling_seconds",
+			Help:    "Latency of completed RPCs, streams included, by method and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "grpc_client_in_flight",
+			Help: "RPCs started but not completed yet by method.",
+		}, []string{"method"}),
+		messagesSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_stream_msg_sent_total",
+			Help: "Stream messages sent by method.",
+		}, []string{"method"}),
+		messagesReceived: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "grpc_client_stream_msg_received_total",
+			Help: "Stream messages received by method.",
+		}, []string{"method"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.requests, m.duration, m.inFlight, m.messagesSent, m.messagesReceived} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *Metrics) start(method string) time.Time {
+	m.inFlight.WithLabelValues(method).Inc()
+	return time.Now()
+}
+
+func (m *Metrics) finish(method string, start time.Time, err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	code := status.Code(err).String()
+	m.inFlight.WithLabelValues(method).Dec()
+	m.requests.WithLabelValues(method, code).Inc()
+	m.duration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+func MetricsUnaryClientInterceptor(m *Metrics) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := m.start(method)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.finish(method, start, err)
+		return err
+	}
+}
+
+// MetricsStreamClientInterceptor completes a stream when RecvMsg returns an
+// error, io.EOF or the single response of a client streaming call, or when
+// its context ends first, as it does for streams the caller abandons.
+func MetricsStreamClientInterceptor(m *Metrics) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := m.start(method)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			m.finish(method, start, err)
+			return nil, err
+		}
+		s := &metricsClientStream{
+			ClientStream:  stream,
+			metrics:       m,
+			method:        method,
+			start:         start,
+			serverStreams: desc.ServerStreams,
+		}
+		s.stop = context.AfterFunc(ctx, func() {
+			s.finish(status.FromContextError(ctx.Err()).Err())
+		})
+		return s, nil
+	}
+}
+
+type metricsClientStream struct {
+	grpc.ClientStream
+
+	metrics       *Metrics
+	method        string
+	start         time.Time
+	serverStreams bool
+
+	// stop unregisters the completion on context end.
+	stop func() bool
+	once sync.Once
+}
+
+func (s *metricsClientStream) finish(err error) {
+	s.once.Do(func() { s.metrics.finish(s.method, s.start, err) })
+}
+
+func (s *metricsClientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.metrics.messagesSent.WithLabelValues(s.method).Inc()
+	}
+	return err
+}
+
+func (s *metricsClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.metrics.messagesReceived.WithLabelValues(s.method).Inc()
+	}
+	if err != nil || !s.serverStreams {
+		s.stop()
+		s.finish(err)
+	}
+	return err
+}
+
+var (
+	breakerStateDesc = prometheus.NewDesc("grpc_client_breaker_state",
+		"Circuit breaker state: 0 closed, 1 half-open, 2 open.", []string{"breaker", "forced"}, nil)
+	breakerFailuresDesc = prometheus.NewDesc("grpc_client_breaker_consecutive_failures",
+		"Consecutive failures counted by the circuit breaker in its current state.", []string{"breaker"}, nil)
+)
+
+// BreakerCollector exports the state of every breaker of registry.
+type BreakerCollector struct {
+	registry *BreakerRegistry
+}
+
+func NewBreakerCollector(registry *BreakerRegistry) *BreakerCollector {
+	return &BreakerCollector{registry: registry}
+}
+
+func (c *BreakerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- breakerStateDesc
+	ch <- breakerFailuresDesc
+}
+
+func (c *BreakerCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, b := range c.registry.Breakers() {
+		forced := "false"
+		if b.Forced {
+			forced = "true"
+		}
+		ch <- prometheus.MustNewConstMetric(breakerStateDesc, prometheus.GaugeValue, breakerStateValue(b.State), b.Key, forced)
+		ch <- prometheus.MustNewConstMetric(breakerFailuresDesc, prometheus.GaugeValue, float64(b.Counts.ConsecutiveFailures), b.Key)
+	}
+}
+
+func breakerStateValue(state gobreaker.State) float64 {
+	switch state {
+	case gobreaker.StateHalfOpen:
+		return 1
+	case gobreaker.StateOpen:
+		return 2
+	}
+	return 0
+}
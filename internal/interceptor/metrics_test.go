@@ -0,0 +1,169 @@
+package interceptor_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	sayHello    = "/hello.HelloService/SayHello"
+	serverHello = "/hello.HelloService/HelloServerStream"
+)
+
+// dialFake starts the fake servers and connects to them through opts.
+func dialFake(t *testing.T, opts ...grpc.DialOption) (*fakeserver.Server, *grpc.ClientConn) {
+	t.Helper()
+	srv := fakeserver.New()
+	t.Cleanup(srv.Close)
+	conn, err := srv.Dial(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return srv, conn
+}
+
+func TestMetricsInterceptors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := interceptor.NewMetrics(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, conn := dialFake(t,
+		grpc.WithChainUnaryInterceptor(interceptor.MetricsUnaryClientInterceptor(m)),
+		grpc.WithChainStreamInterceptor(interceptor.MetricsStreamClientInterceptor(m)))
+	client := hello.NewHelloServiceClient(conn)
+	ctx := context.Background()
+
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	srv.Hello.SayHello.Fail(fakeserver.Error(codes.Unavailable, "down"))
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "b"}); err == nil {
+		t.Fatal("scripted failure did not fail")
+	}
+
+	stream, err := client.HelloServerStream(ctx, &hello.HelloRequest{Name: "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	inFlight := metric(t, reg, "grpc_client_in_flight", map[string]string{"method": serverHello})
+	if got := inFlight.GetGauge().GetValue(); got != 1 {
+		t.Errorf("in flight during the stream = %v, want 1", got)
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := `
+# HELP grpc_client_handled_total Completed RPCs by method and status code.
+# TYPE grpc_client_handled_total counter
+grpc_client_handled_total{code="OK",method="/hello.HelloService/HelloServerStream"} 1
+grpc_client_handled_total{code="OK",method="/hello.HelloService/SayHello"} 1
+grpc_client_handled_total{code="Unavailable",method="/hello.HelloService/SayHello"} 1
+# HELP grpc_client_in_flight RPCs started but not completed yet by method.
+# TYPE grpc_client_in_flight gauge
+grpc_client_in_flight{method="/hello.HelloService/HelloServerStream"} 0
+grpc_client_in_flight{method="/hello.HelloService/SayHello"} 0
+# HELP grpc_client_stream_msg_received_total Stream messages received by method.
+# TYPE grpc_client_stream_msg_received_total counter
+grpc_client_stream_msg_received_total{method="/hello.HelloService/HelloServerStream"} 10
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"grpc_client_handled_total", "grpc_client_in_flight", "grpc_client_stream_msg_received_total"); err != nil {
+		t.Error(err)
+	}
+
+	// One observation per completed call.
+	if got := testutil.CollectAndCount(reg, "grpc_client_handling_seconds"); got != 3 {
+		t.Errorf("handling_seconds series = %v, want 3", got)
+	}
+	failed := metric(t, reg, "grpc_client_handling_seconds", map[string]string{"method": sayHello, "code": "Unavailable"})
+	if got := failed.GetHistogram().GetSampleCount(); got != 1 {
+		t.Errorf("handling_seconds count for the failed call = %v, want 1", got)
+	}
+}
+
+func TestMetricsAbandonedStream(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := interceptor.NewMetrics(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, conn := dialFake(t, grpc.WithChainStreamInterceptor(interceptor.MetricsStreamClientInterceptor(m)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := hello.NewHelloServiceClient(conn).HelloServerStream(ctx, &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	// The duration is observed last.
+	deadline := time.Now().Add(5 * time.Second)
+	for testutil.CollectAndCount(reg, "grpc_client_handling_seconds") == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("abandoned stream never completed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	canceled := metric(t, reg, "grpc_client_handling_seconds", map[string]string{"method": serverHello, "code": "Canceled"})
+	if got := canceled.GetHistogram().GetSampleCount(); got != 1 {
+		t.Errorf("handling_seconds count for the abandoned stream = %v, want 1", got)
+	}
+	inFlight := metric(t, reg, "grpc_client_in_flight", map[string]string{"method": serverHello})
+	if got := inFlight.GetGauge().GetValue(); got != 0 {
+		t.Errorf("in flight after the abandoned stream = %v, want 0", got)
+	}
+	handled := metric(t, reg, "grpc_client_handled_total", map[string]string{"method": serverHello, "code": "Canceled"})
+	if got := handled.GetCounter().GetValue(); got != 1 {
+		t.Errorf("handled_total for the abandoned stream = %v, want 1", got)
+	}
+}
+
+// metric finds the series of name carrying labels.
+func metric(t *testing.T, reg *prometheus.Registry, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	series:
+		for _, m := range f.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if want, ok := labels[label.GetName()]; ok && want != label.GetValue() {
+					continue series
+				}
+			}
+			return m
+		}
+	}
+	t.Fatalf("no %v series with %v", name, labels)
+	return nil
+}
@@ -0,0 +1,239 @@
+package interceptor
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/recording"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// RecordUnaryClientInterceptor records every call to rec. It belongs at the
+// end of the chain, so each retry attempt is recorded as it was sent.
+func RecordUnaryClientInterceptor(rec *recording.Recorder) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		e := rec.Begin(method, recording.KindUnary, md)
+		record(method, e.AddRequest(req))
+
+		var header, trailer metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header), grpc.Trailer(&trailer))...)
+		if err == nil {
+			record(method, e.AddResponse(reply))
+		}
+		e.Finish(header, trailer, err)
+		return err
+	}
+}
+
+// RecordStreamClientInterceptor records every stream to rec once it ended,
+// that is when RecvMsg returns an error, io.EOF or the single response of a
+// client streaming call.
+func RecordStreamClientInterceptor(rec *recording.Recorder) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		e := rec.Begin(method, recording.StreamKind(desc), md)
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			e.Finish(nil, nil, err)
+			return nil, err
+		}
+		return &recordClientStream{ClientStream: stream, method: method, serverStreams: desc.ServerStreams, exchange: e}, nil
+	}
+}
+
+// record logs the messages that could not be recorded, the call itself
+// goes on.
+func record(method string, err error) {
+	if err != nil {
+		slog.Warn("Failed to record message", "method", method, "error", err)
+	}
+}
+
+type recordClientStream struct {
+	grpc.ClientStream
+
+	method        string
+	serverStreams bool
+	exchange      *recording.Exchange
+
+	once sync.Once
+}
+
+func (s *recordClientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		record(s.method, s.exchange.AddRequest(m))
+	}
+	return err
+}
+
+func (s *recordClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		record(s.method, s.exchange.AddResponse(m))
+	}
+	if err != nil || !s.serverStreams {
+		s.once.Do(func() {
+			header, _ := s.ClientStream.Header()
+			s.exchange.Finish(header, s.ClientStream.Trailer(), err)
+		})
+	}
+	return err
+}
+
+type ReplayOptions struct {
+	Replayer *recording.Replayer
+
+	// Strict fails calls whose requests differ from the recorded ones with
+	// codes.FailedPrecondition.
+	Strict bool
+	// Redactor is the one the recording was made with. In strict mode a
+	// request also matches its recording once redacted.
+	Redactor *redact.Redactor
+}
+
+// ReplayUnaryClientInterceptor answers every call from the recording
+// without reaching the server. Like the record interceptor it belongs at the
+// end of the chain.
+func ReplayUnaryClientInterceptor(opts ReplayOptions) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		e, err := opts.Replayer.Next(method)
+		if err != nil {
+			return err
+		}
+		if opts.Strict {
+			if err := matchRequest(e, 0, req, opts.Redactor); err != nil {
+				return err
+			}
+		}
+
+		for _, o := range callOpts {
+			switch o := o.(type) {
+			case grpc.HeaderCallOption:
+				*o.HeaderAddr = metadata.MD(e.Header).Copy()
+			case grpc.TrailerCallOption:
+				*o.TrailerAddr = metadata.MD(e.Trailer).Copy()
+			}
+		}
+
+		if err := e.Err(); err != nil {
+			return err
+		}
+		ok, err := e.Response(0, reply)
+		if err != nil {
+			return status.Errorf(codes.Internal, "replay: %v", err)
+		}
+		if !ok {
+			return status.Errorf(codes.Internal, "replay: recorded %v has no response", method)
+		}
+		return nil
+	}
+}
+
+// ReplayStreamClientInterceptor is the stream counterpart of
+// ReplayUnaryClientInterceptor. The recorded responses are served as fast as
+// they are read, whatever the client sends, until the call's context ends.
+func ReplayStreamClientInterceptor(opts ReplayOptions) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		e, err := opts.Replayer.Next(method)
+		if err != nil {
+			return nil, err
+		}
+		return &replayClientStream{ctx: ctx, exchange: e, strict: opts.Strict, redactor: opts.Redactor}, nil
+	}
+}
+
+type replayClientStream struct {
+	ctx      context.Context
+	exchange *recording.Exchange
+	strict   bool
+	redactor *redact.Redactor
+
+	mu     sync.Mutex
+	sent   int
+	recv   int
+	closed bool
+}
+
+func (s *replayClientStream) Header() (metadata.MD, error) {
+	return metadata.MD(s.exchange.Header).Copy(), nil
+}
+
+func (s *replayClientStream) Trailer() metadata.MD {
+	return metadata.MD(s.exchange.Trailer).Copy()
+}
+
+func (s *replayClientStream) CloseSend() error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *replayClientStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *replayClientStream) SendMsg(m any) error {
+	if err := s.ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	s.mu.Lock()
+	i, closed := s.sent, s.closed
+	s.sent++
+	s.mu.Unlock()
+	if closed {
+		return status.Error(codes.Internal, "SendMsg called after CloseSend")
+	}
+
+	if s.strict {
+		return matchRequest(s.exchange, i, m, s.redactor)
+	}
+	return nil
+}
+
+func (s *replayClientStream) RecvMsg(m any) error {
+	if err := s.ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	s.mu.Lock()
+	i := s.recv
+	s.recv++
+	s.mu.Unlock()
+
+	ok, err := s.exchange.Response(i, m)
+	if err != nil {
+		return status.Errorf(codes.Internal, "replay: %v", err)
+	}
+	if ok {
+		return nil
+	}
+	if err := s.exchange.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+func matchRequest(e *recording.Exchange, i int, req any, redactor *redact.Redactor) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return status.Errorf(codes.Internal, "replay: %T is not a proto message", req)
+	}
+	want := msg.ProtoReflect().New().Interface()
+	if err := e.Request(i, want); err != nil {
+		return status.Errorf(codes.FailedPrecondition, "replay: %v", err)
+	}
+	if !proto.Equal(want, msg) && (redactor == nil || !proto.Equal(want, redactor.Proto(msg))) {
+		return status.Errorf(codes.FailedPrecondition, "replay: request %d of %v differs from the recording", i, e.Method)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package interceptor_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/recording"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// replayer returns a replayer holding one exchange of method with responses.
+func replayer(t *testing.T, method string, kind recording.Kind, responses ...any) *recording.Replayer {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "calls.json")
+	rec, err := recording.Create(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := rec.Begin(method, kind, nil)
+	for _, resp := range responses {
+		if err := e.AddResponse(resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e.Finish(nil, nil, nil)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := recording.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func dialReplay(t *testing.T, r *recording.Replayer) bank.BankServiceClient {
+	t.Helper()
+	opts := interceptor.ReplayOptions{Replayer: r}
+	_, cc := dialFake(t,
+		grpc.WithChainUnaryInterceptor(interceptor.ReplayUnaryClientInterceptor(opts)),
+		grpc.WithChainStreamInterceptor(interceptor.ReplayStreamClientInterceptor(opts)))
+	return bank.NewBankServiceClient(cc)
+}
+
+func TestReplayStreamStopsWithContext(t *testing.T) {
+	r := replayer(t, "/bank.BankService/FetchExchangeRates", recording.KindServerStream,
+		&bank.ExchangeRateResponse{Rate: 1.1}, &bank.ExchangeRateResponse{Rate: 1.2})
+	client := dialReplay(t, r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.FetchExchangeRates(ctx, &bank.ExchangeRateRequest{FromCurrency: "USD", ToCurrency: "EUR"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("Recv() after cancel = %v, want Canceled", err)
+	}
+}
+
+func TestReplaySendAfterCloseSend(t *testing.T) {
+	r := replayer(t, "/bank.BankService/SummarizeTransactions", recording.KindClientStream, &bank.TransactionSummary{})
+	client := dialReplay(t, r)
+
+	stream, err := client.SummarizeTransactions(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&bank.Transaction{Amount: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&bank.Transaction{Amount: 20}); status.Code(err) != codes.Internal {
+		t.Errorf("Send() after CloseSend = %v, want Internal", err)
+	}
+}
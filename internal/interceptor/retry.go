@@ -0,0 +1,281 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/application/domain/rpcerror"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NonIdempotentMethods are never retried unless their policy sets
+// AllowNonIdempotent, a retry could apply the same change twice.
+var NonIdempotentMethods = []string{
+	bank.BankService_TransferMultiple_FullMethodName,
+	bank.BankService_CreateAccount_FullMethodName,
+}
+
+// Backoff returns the delay before the given retry, attempt starts at 1.
+type Backoff func(attempt int) time.Duration
+
+// BackoffExponential doubles base on every attempt up to max. jitter is the
+// fraction, between 0 and 1, by which each delay is randomly shortened or
+// lengthened.
+func BackoffExponential(base, max time.Duration, jitter float64) Backoff {
+	return func(attempt int) time.Duration {
+		d := base << (attempt - 1)
+		if d <= 0 || (max > 0 && d > max) {
+			d = max
+		}
+		return withJitter(d, jitter)
+	}
+}
+
+// BackoffLinear waits step, 2*step, 3*step... with the same jitter as
+// BackoffExponential.
+func BackoffLinear(step time.Duration, jitter float64) Backoff {
+	return func(attempt int) time.Duration {
+		return withJitter(step*time.Duration(attempt), jitter)
+	}
+}
+
+func withJitter(d time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 || d <= 0 {
+		return d
+	}
+	delta := float64(d) * jitter
+	return time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+}
+
+type RetryPolicy struct {
+	// Codes lists the status codes worth retrying.
+	Codes []codes.Code
+
+	// MaxAttempts counts the first call, 1 disables retries.
+	MaxAttempts int
+	Backoff     Backoff
+
+	// PerAttemptTimeout bounds each unary attempt, zero means only the
+	// caller deadline applies. It is not applied to streams.
+	PerAttemptTimeout time.Duration
+
+	// AllowNonIdempotent opts a method listed in NonIdempotentMethods in.
+	AllowNonIdempotent bool
+}
+
+type RetryOptions struct {
+	Default RetryPolicy
+
+	// Methods overrides Default per full method name, e.g.
+	// "/bank.BankService/GetCurrentBalance".
+	Methods map[string]RetryPolicy
+}
+
+func (o RetryOptions) policy(method string) (RetryPolicy, bool) {
+	p, ok := o.Methods[method]
+	if !ok {
+		p = o.Default
+	}
+	if p.MaxAttempts <= 1 || len(p.Codes) == 0 {
+		return p, false
+	}
+	if slices.Contains(NonIdempotentMethods, method) && !p.AllowNonIdempotent {
+		return p, false
+	}
+	return p, true
+}
+
+// delay returns how long to wait before retrying after err. A RetryInfo
+// detail sent by the server takes precedence over the backoff.
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	if d, ok := rpcerror.DetailsOf(err).RetryDelay(); ok {
+		return d
+	}
+	if p.Backoff == nil {
+		return 0
+	}
+	return p.Backoff(attempt)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	return slices.Contains(p.Codes, status.Code(err))
+}
+
+// waitRetry sleeps for d unless ctx ends first.
+func waitRetry(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func RetryUnaryClientInterceptor(opts RetryOptions) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		policy, ok := opts.policy(method)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+
+		var err error
+		for attempt := 1; ; attempt++ {
+			err = invokeAttempt(ctx, policy.PerAttemptTimeout, method, req, reply, cc, invoker, callOpts...)
+			if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+				return err
+			}
+
+			delay := policy.delay(attempt, err)
+			slog.Warn("Retrying call", "method", method, "delay", delay, "attempt", attempt+1, "max_attempts", policy.MaxAttempts, "error", redact.Default().Error(err))
+			if waitErr := waitRetry(ctx, delay); waitErr != nil {
+				return status.FromContextError(waitErr).Err()
+			}
+		}
+	}
+}
+
+func invokeAttempt(ctx context.Context, timeout time.Duration, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// RetryStreamClientInterceptor retries opening the stream. Server streaming
+// calls are also retried when the first receive fails, since the single
+// request can be replayed; once a message was received, or for client and
+// bidirectional streams once the stream is open, errors are returned as is.
+func RetryStreamClientInterceptor(opts RetryOptions) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		policy, ok := opts.policy(method)
+		if !ok {
+			return streamer(ctx, desc, cc, method, callOpts...)
+		}
+
+		var stream grpc.ClientStream
+		var err error
+		attempt := 1
+		for ; ; attempt++ {
+			stream, err = streamer(ctx, desc, cc, method, callOpts...)
+			if err == nil || attempt >= policy.MaxAttempts || !policy.retryable(err) {
+				break
+			}
+			if waitErr := waitRetry(ctx, policy.delay(attempt, err)); waitErr != nil {
+				return nil, status.FromContextError(waitErr).Err()
+			}
+		}
+		if err != nil || desc.ClientStreams {
+			return stream, err
+		}
+		return &retryServerStream{
+			ClientStream: stream,
+			ctx:          ctx,
+			desc:         desc,
+			cc:           cc,
+			method:       method,
+			streamer:     streamer,
+			callOpts:     callOpts,
+			policy:       policy,
+			attempt:      attempt,
+		}, nil
+	}
+}
+
+// retryServerStream replays the request of a server streaming call on a new
+// stream when nothing was received yet.
+type retryServerStream struct {
+	grpc.ClientStream
+
+	ctx      context.Context
+	desc     *grpc.StreamDesc
+	cc       *grpc.ClientConn
+	method   string
+	streamer grpc.Streamer
+	callOpts []grpc.CallOption
+	policy   RetryPolicy
+
+	mu       sync.Mutex
+	request  any
+	closed   bool
+	received bool
+	attempt  int
+}
+
+func (s *retryServerStream) current() grpc.ClientStream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ClientStream
+}
+
+func (s *retryServerStream) SendMsg(m any) error {
+	s.mu.Lock()
+	s.request = m
+	stream := s.ClientStream
+	s.mu.Unlock()
+	return stream.SendMsg(m)
+}
+
+func (s *retryServerStream) CloseSend() error {
+	s.mu.Lock()
+	s.closed = true
+	stream := s.ClientStream
+	s.mu.Unlock()
+	return stream.CloseSend()
+}
+
+func (s *retryServerStream) RecvMsg(m any) error {
+	err := s.current().RecvMsg(m)
+	for {
+		s.mu.Lock()
+		if err == nil {
+			s.received = true
+		}
+		if err == nil || errors.Is(err, io.EOF) || s.received || s.request == nil ||
+			s.attempt >= s.policy.MaxAttempts || !s.policy.retryable(err) {
+			s.mu.Unlock()
+			return err
+		}
+		delay := s.policy.delay(s.attempt, err)
+		s.attempt++
+		request := s.request
+		s.mu.Unlock()
+
+		// The lock is not held while waiting so SendMsg and CloseSend
+		// do not block on the backoff.
+		if waitErr := waitRetry(s.ctx, delay); waitErr != nil {
+			return status.FromContextError(waitErr).Err()
+		}
+		stream, openErr := s.streamer(s.ctx, s.desc, s.cc, s.method, s.callOpts...)
+		if openErr != nil {
+			err = openErr
+			continue
+		}
+		// A failed send is reported with its real status by RecvMsg
+		sendErr := stream.SendMsg(request)
+		s.mu.Lock()
+		s.ClientStream = stream
+		closed := s.closed
+		s.mu.Unlock()
+		if sendErr == nil && closed {
+			stream.CloseSend()
+		}
+		err = stream.RecvMsg(m)
+	}
+}
@@ -0,0 +1,190 @@
+package interceptor_test
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func dialRetry(t *testing.T, policy interceptor.RetryPolicy) (*fakeserver.Server, *grpc.ClientConn) {
+	t.Helper()
+	opts := interceptor.RetryOptions{Default: policy}
+	return dialFake(t,
+		grpc.WithChainUnaryInterceptor(interceptor.RetryUnaryClientInterceptor(opts)),
+		grpc.WithChainStreamInterceptor(interceptor.RetryStreamClientInterceptor(opts)))
+}
+
+func TestRetryUnary(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  []error
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		{
+			name:      "retryable code",
+			failures:  []error{fakeserver.Error(codes.Unavailable, "down")},
+			wantCode:  codes.OK,
+			wantCalls: 2,
+		},
+		{
+			name:      "other code",
+			failures:  []error{fakeserver.Error(codes.NotFound, "gone")},
+			wantCode:  codes.NotFound,
+			wantCalls: 1,
+		},
+		{
+			name: "max attempts",
+			failures: []error{
+				fakeserver.Error(codes.Unavailable, "down"),
+				fakeserver.Error(codes.Unavailable, "down"),
+				fakeserver.Error(codes.Unavailable, "still down"),
+				fakeserver.Error(codes.Unavailable, "down again"),
+			},
+			wantCode:  codes.Unavailable,
+			wantCalls: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, conn := dialRetry(t, interceptor.RetryPolicy{Codes: []codes.Code{codes.Unavailable}, MaxAttempts: 3})
+			for _, err := range tt.failures {
+				srv.Hello.SayHello.Fail(err)
+			}
+
+			_, err := hello.NewHelloServiceClient(conn).SayHello(context.Background(), &hello.HelloRequest{Name: "a"})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("SayHello() = %v, want %v", err, tt.wantCode)
+			}
+			if got := len(srv.Hello.SayHello.Calls()); got != tt.wantCalls {
+				t.Errorf("calls = %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryInfoTakesPrecedence(t *testing.T) {
+	var backoffs atomic.Int32
+	srv, conn := dialRetry(t, interceptor.RetryPolicy{
+		Codes:       []codes.Code{codes.ResourceExhausted},
+		MaxAttempts: 2,
+		Backoff: func(int) time.Duration {
+			backoffs.Add(1)
+			return time.Hour
+		},
+	})
+	srv.Hello.SayHello.Fail(fakeserver.RateLimited(10 * time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := hello.NewHelloServiceClient(conn).SayHello(ctx, &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatalf("SayHello() = %v, want the retry after the server delay to succeed", err)
+	}
+	if n := backoffs.Load(); n != 0 {
+		t.Errorf("backoff consulted %v times, want the RetryInfo delay only", n)
+	}
+}
+
+func TestRetryPerAttemptTimeout(t *testing.T) {
+	srv, conn := dialRetry(t, interceptor.RetryPolicy{
+		Codes:             []codes.Code{codes.DeadlineExceeded},
+		MaxAttempts:       2,
+		PerAttemptTimeout: 50 * time.Millisecond,
+	})
+	srv.Hello.SayHello.Push(fakeserver.Step[hello.HelloResponse]{Delay: time.Minute})
+
+	start := time.Now()
+	resp, err := hello.NewHelloServiceClient(conn).SayHello(context.Background(), &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatalf("SayHello() = %v, want the second attempt to succeed", err)
+	}
+	if resp.GetGreet() != "Hello a" {
+		t.Errorf("Greet = %q", resp.GetGreet())
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %v, the first attempt was not cut short", elapsed)
+	}
+}
+
+func TestRetrySkipsNonIdempotentMethods(t *testing.T) {
+	for _, allow := range []bool{false, true} {
+		srv, conn := dialRetry(t, interceptor.RetryPolicy{
+			Codes:              []codes.Code{codes.Unavailable},
+			MaxAttempts:        3,
+			AllowNonIdempotent: allow,
+		})
+		srv.Bank.CreateAccount.Fail(fakeserver.Error(codes.Unavailable, "down"))
+
+		bank.NewBankServiceClient(conn).CreateAccount(context.Background(),
+			&bank.AccountRequest{AccountName: "acc", Currency: "USD", InitialDepositAmount: 1})
+
+		want := 1
+		if allow {
+			want = 2
+		}
+		if got := len(srv.Bank.CreateAccount.Calls()); got != want {
+			t.Errorf("AllowNonIdempotent %v: calls = %v, want %v", allow, got, want)
+		}
+	}
+}
+
+func TestRetryServerStreamReplaysRequest(t *testing.T) {
+	srv, conn := dialRetry(t, interceptor.RetryPolicy{Codes: []codes.Code{codes.Unavailable}, MaxAttempts: 3})
+	srv.Hello.HelloServerStream.Fail(fakeserver.Error(codes.Unavailable, "down"))
+
+	stream, err := hello.NewHelloServiceClient(conn).HelloServerStream(context.Background(), &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received int
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv() = %v, want the replayed stream", err)
+		}
+		received++
+	}
+	if received != fakeserver.StreamLength {
+		t.Errorf("received %v messages, want %v", received, fakeserver.StreamLength)
+	}
+	calls := srv.Hello.HelloServerStream.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("calls = %v, want 2", len(calls))
+	}
+	if got := calls[1].Requests[0].GetName(); got != "a" {
+		t.Errorf("replayed request name = %q, want %q", got, "a")
+	}
+}
+
+func TestRetryServerStreamNotReplayedAfterMessage(t *testing.T) {
+	srv, conn := dialRetry(t, interceptor.RetryPolicy{Codes: []codes.Code{codes.Unavailable}, MaxAttempts: 3})
+	srv.Hello.HelloServerStream.Push(fakeserver.Step[hello.HelloResponse]{
+		Responses: []*hello.HelloResponse{{Greet: "first"}},
+		Err:       fakeserver.Error(codes.Unavailable, "down"),
+	})
+
+	stream, err := hello.NewHelloServiceClient(conn).HelloServerStream(context.Background(), &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Errorf("Recv() = %v, want Unavailable", err)
+	}
+	if got := len(srv.Hello.HelloServerStream.Calls()); got != 1 {
+		t.Errorf("calls = %v, want 1", got)
+	}
+}
@@ -0,0 +1,164 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type TimeoutOptions struct {
+	// Default applies to calls without an entry in Methods, zero disables it.
+	Default time.Duration
+
+	// Methods overrides Default per full method name, zero disables the
+	// timeout of that method.
+	Methods map[string]time.Duration
+}
+
+func (o TimeoutOptions) timeout(method string) time.Duration {
+	if t, ok := o.Methods[method]; ok {
+		return t
+	}
+	return o.Default
+}
+
+// TimeoutError is returned when a call ran out of the time given by a
+// timeout interceptor, as opposed to a deadline set by the caller. Its gRPC
+// code is still DeadlineExceeded.
+type TimeoutError struct {
+	Method  string
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%v exceeded the client timeout of %v", e.Method, e.Timeout)
+}
+
+func (e *TimeoutError) GRPCStatus() *status.Status {
+	return status.New(codes.DeadlineExceeded, e.Error())
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+func (e *TimeoutError) Is(target error) bool {
+	return target == context.DeadlineExceeded
+}
+
+// withTimeout derives the context of a call. It returns ctx unchanged, with
+// a nil cancel, when there is no timeout or the caller already set an
+// earlier deadline.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+		return ctx, nil
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// timeoutError turns err into a TimeoutError when it was caused by the
+// deadline of callCtx rather than by parent.
+func timeoutError(parent, callCtx context.Context, method string, timeout time.Duration, err error) error {
+	if status.Code(err) != codes.DeadlineExceeded || callCtx.Err() == nil || parent.Err() != nil {
+		return err
+	}
+	return &TimeoutError{Method: method, Timeout: timeout, Err: err}
+}
+
+func TimeoutUnaryClientInterceptor(opts TimeoutOptions) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		timeout := opts.timeout(method)
+		callCtx, cancel := withTimeout(ctx, timeout)
+		if cancel == nil {
+			return invoker(ctx, method, req, reply, cc, callOpts...)
+		}
+		defer cancel()
+
+		err := invoker(callCtx, method, req, reply, cc, callOpts...)
+		return timeoutError(ctx, callCtx, method, timeout, err)
+	}
+}
+
+// TimeoutStreamClientInterceptor bounds the whole stream, not single
+// messages. The timer is released once the stream ends, that is when RecvMsg
+// returns an error, io.EOF or the single response of a client streaming
+// call.
+func TimeoutStreamClientInterceptor(opts TimeoutOptions) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		timeout := opts.timeout(method)
+		callCtx, cancel := withTimeout(ctx, timeout)
+		if cancel == nil {
+			return streamer(ctx, desc, cc, method, callOpts...)
+		}
+
+		stream, err := streamer(callCtx, desc, cc, method, callOpts...)
+		if err != nil {
+			err = timeoutError(ctx, callCtx, method, timeout, err)
+			cancel()
+			return nil, err
+		}
+		return &timeoutClientStream{
+			ClientStream:  stream,
+			parent:        ctx,
+			ctx:           callCtx,
+			method:        method,
+			timeout:       timeout,
+			serverStreams: desc.ServerStreams,
+			cancel:        cancel,
+		}, nil
+	}
+}
+
+// UnaryTimeoutInterceptor applies the same timeout to every unary call.
+//
+// Deprecated: use TimeoutUnaryClientInterceptor.
+func UnaryTimeoutInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return TimeoutUnaryClientInterceptor(TimeoutOptions{Default: timeout})
+}
+
+// TimeoutStreamClientIntereptor applies the same timeout to every stream.
+//
+// Deprecated: use TimeoutStreamClientInterceptor.
+func TimeoutStreamClientIntereptor(timeout time.Duration) grpc.StreamClientInterceptor {
+	return TimeoutStreamClientInterceptor(TimeoutOptions{Default: timeout})
+}
+
+type timeoutClientStream struct {
+	grpc.ClientStream
+
+	parent  context.Context
+	ctx     context.Context
+	method  string
+	timeout time.Duration
+
+	// Without server streaming the single response ends the call.
+	serverStreams bool
+
+	once   sync.Once
+	cancel context.CancelFunc
+}
+
+func (s *timeoutClientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	return timeoutError(s.parent, s.ctx, s.method, s.timeout, err)
+}
+
+func (s *timeoutClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		err = timeoutError(s.parent, s.ctx, s.method, s.timeout, err)
+	}
+	if err != nil || !s.serverStreams {
+		s.once.Do(s.cancel)
+	}
+	return err
+}
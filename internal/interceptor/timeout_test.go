@@ -0,0 +1,151 @@
+package interceptor_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// deadlines records the deadline each call reaches the server with.
+type deadlines struct {
+	last time.Time
+	ok   bool
+}
+
+func (d *deadlines) unary(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	d.last, d.ok = ctx.Deadline()
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (d *deadlines) stream(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	d.last, d.ok = ctx.Deadline()
+	return streamer(ctx, desc, cc, method, opts...)
+}
+
+func dialTimeout(t *testing.T, opts interceptor.TimeoutOptions) (*fakeserver.Server, hello.HelloServiceClient, *deadlines) {
+	t.Helper()
+	d := &deadlines{}
+	srv, conn := dialFake(t,
+		grpc.WithChainUnaryInterceptor(interceptor.TimeoutUnaryClientInterceptor(opts), d.unary),
+		grpc.WithChainStreamInterceptor(interceptor.TimeoutStreamClientInterceptor(opts), d.stream))
+	return srv, hello.NewHelloServiceClient(conn), d
+}
+
+func TestTimeoutPerMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		methods map[string]time.Duration
+		want    time.Duration
+	}{
+		{name: "default", want: time.Hour},
+		{name: "method override", methods: map[string]time.Duration{sayHello: time.Minute}, want: time.Minute},
+		{name: "other method", methods: map[string]time.Duration{serverHello: time.Minute}, want: time.Hour},
+		{name: "disabled for method", methods: map[string]time.Duration{sayHello: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client, d := dialTimeout(t, interceptor.TimeoutOptions{Default: time.Hour, Methods: tt.methods})
+			start := time.Now()
+			if _, err := client.SayHello(context.Background(), &hello.HelloRequest{Name: "a"}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == 0 {
+				if d.ok {
+					t.Errorf("deadline in %v, want none", d.last.Sub(start))
+				}
+				return
+			}
+			if !d.ok {
+				t.Fatalf("no deadline, want %v", tt.want)
+			}
+			if got := d.last.Sub(start); got < tt.want || got > tt.want+time.Second {
+				t.Errorf("deadline in %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutKeepsShorterParentDeadline(t *testing.T) {
+	_, client, d := dialTimeout(t, interceptor.TimeoutOptions{Default: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	parent, _ := ctx.Deadline()
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if !d.ok || !d.last.Equal(parent) {
+		t.Errorf("deadline = %v, want the parent deadline %v", d.last, parent)
+	}
+}
+
+func TestTimeoutError(t *testing.T) {
+	srv, client, _ := dialTimeout(t, interceptor.TimeoutOptions{Default: 50 * time.Millisecond})
+
+	srv.Hello.SayHello.Push(fakeserver.Step[hello.HelloResponse]{Delay: time.Minute})
+	_, err := client.SayHello(context.Background(), &hello.HelloRequest{Name: "a"})
+	var timeoutErr *interceptor.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Method != sayHello {
+		t.Fatalf("SayHello() = %v, want a TimeoutError", err)
+	}
+	if status.Code(err) != codes.DeadlineExceeded || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SayHello() = %v, want it to be DeadlineExceeded", err)
+	}
+
+	// The caller's own deadline is not reported as the client timeout.
+	srv.Hello.SayHello.Push(fakeserver.Step[hello.HelloResponse]{Delay: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.SayHello(ctx, &hello.HelloRequest{Name: "a"})
+	if errors.As(err, &timeoutErr) || status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("SayHello() = %v, want the caller deadline", err)
+	}
+}
+
+func TestTimeoutStream(t *testing.T) {
+	srv, client, d := dialTimeout(t, interceptor.TimeoutOptions{Methods: map[string]time.Duration{serverHello: 100 * time.Millisecond}})
+
+	stream, err := client.HelloServerStream(context.Background(), &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.ok {
+		t.Error("stream has no deadline")
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv() = %v, want the stream to finish in time", err)
+		}
+	}
+
+	// The timeout bounds the whole stream, not single messages.
+	srv.Hello.HelloServerStream.Push(fakeserver.Step[hello.HelloResponse]{
+		Responses: []*hello.HelloResponse{{Greet: "1"}, {Greet: "2"}, {Greet: "3"}},
+		Interval:  60 * time.Millisecond,
+	})
+	stream, err = client.HelloServerStream(context.Background(), &hello.HelloRequest{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		_, err = stream.Recv()
+		if err != nil {
+			break
+		}
+	}
+	var timeoutErr *interceptor.TimeoutError
+	if !errors.As(err, &timeoutErr) || timeoutErr.Timeout != 100*time.Millisecond {
+		t.Errorf("Recv() = %v, want a TimeoutError", err)
+	}
+}
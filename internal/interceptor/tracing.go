@@ -0,0 +1,175 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"github.com/VallabhSLEPAM/grpc-client/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type TracingOptions struct {
+	// TracerProvider defaults to otel.GetTracerProvider(). Tests can pass a
+	// provider with a tracetest.SpanRecorder.
+	TracerProvider trace.TracerProvider
+
+	// Propagator defaults to otel.GetTextMapPropagator().
+	Propagator propagation.TextMapPropagator
+}
+
+func (o TracingOptions) tracer() trace.Tracer {
+	if o.TracerProvider == nil {
+		return otel.Tracer(tracing.TracerName)
+	}
+	return o.TracerProvider.Tracer(tracing.TracerName)
+}
+
+func (o TracingOptions) propagator() propagation.TextMapPropagator {
+	if o.Propagator == nil {
+		return otel.GetTextMapPropagator()
+	}
+	return o.Propagator
+}
+
+// start opens the client span of a call and injects its context, usually as
+// a W3C traceparent header, into the outgoing metadata.
+func (o TracingOptions) start(ctx context.Context, cc *grpc.ClientConn, method string) (context.Context, trace.Span) {
+	name := strings.TrimPrefix(method, "/")
+	service, rpcMethod, _ := strings.Cut(name, "/")
+
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", rpcMethod),
+	}
+	if cc != nil {
+		attrs = append(attrs, attribute.String("server.address", cc.Target()))
+	}
+	ctx, span := o.tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	o.propagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+func endSpan(span trace.Span, err error) {
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	code := status.Code(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(code)))
+	if code != codes.OK {
+		span.SetStatus(otelcodes.Error, redact.Default().String(status.Convert(err).Message()))
+	}
+	span.End()
+}
+
+func TracingUnaryClientInterceptor(opts TracingOptions) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		ctx, span := opts.start(ctx, cc, method)
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		endSpan(span, err)
+		return err
+	}
+}
+
+// TracingStreamClientInterceptor adds a span event for every message. The
+// span ends when RecvMsg returns an error, io.EOF or the single response of
+// a client streaming call.
+func TracingStreamClientInterceptor(opts TracingOptions) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, span := opts.start(ctx, cc, method)
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			endSpan(span, err)
+			return nil, err
+		}
+		return &tracingClientStream{
+			ClientStream:  stream,
+			span:          span,
+			serverStreams: desc.ServerStreams,
+		}, nil
+	}
+}
+
+type tracingClientStream struct {
+	grpc.ClientStream
+
+	span          trace.Span
+	serverStreams bool
+
+	mu             sync.Mutex
+	sent, received int
+	once           sync.Once
+}
+
+func (s *tracingClientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		s.sent++
+		id := s.sent
+		s.mu.Unlock()
+		s.messageEvent("SENT", id, m)
+	}
+	return err
+}
+
+func (s *tracingClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err == nil {
+		s.mu.Lock()
+		s.received++
+		id := s.received
+		s.mu.Unlock()
+		s.messageEvent("RECEIVED", id, m)
+	}
+	if err != nil || !s.serverStreams {
+		s.once.Do(func() { endSpan(s.span, err) })
+	}
+	return err
+}
+
+func (s *tracingClientStream) messageEvent(kind string, id int, m any) {
+	s.span.AddEvent("message", trace.WithAttributes(
+		attribute.String("message.type", kind),
+		attribute.Int("message.id", id),
+		attribute.Int("message.uncompressed_size", messageSize(m)),
+	))
+}
+
+// metadataCarrier lets propagators write gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,127 @@
+package interceptor_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"github.com/VallabhSLEPAM/grpc-client/internal/testing/fakeserver"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func dialTraced(t *testing.T, opts interceptor.TracingOptions) (*fakeserver.Server, hello.HelloServiceClient) {
+	t.Helper()
+	srv, conn := dialFake(t,
+		grpc.WithChainUnaryInterceptor(interceptor.TracingUnaryClientInterceptor(opts)),
+		grpc.WithChainStreamInterceptor(interceptor.TracingStreamClientInterceptor(opts)))
+	return srv, hello.NewHelloServiceClient(conn)
+}
+
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingInterceptors(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	opts := interceptor.TracingOptions{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)),
+		Propagator:     propagation.TraceContext{},
+	}
+	srv, client := dialTraced(t, opts)
+	ctx := context.Background()
+
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	srv.Hello.SayHello.Fail(fakeserver.Error(codes.Unavailable, "down"))
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "b"}); err == nil {
+		t.Fatal("scripted failure did not fail")
+	}
+	stream, err := client.HelloServerStream(ctx, &hello.HelloRequest{Name: "c"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := stream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spans := rec.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("got %v ended spans, want 3", len(spans))
+	}
+	ok, failed, streamed := spans[0], spans[1], spans[2]
+
+	if ok.Name() != "hello.HelloService/SayHello" || ok.SpanKind() != trace.SpanKindClient {
+		t.Errorf("span = %v of kind %v, want hello.HelloService/SayHello of kind client", ok.Name(), ok.SpanKind())
+	}
+	if got := attr(ok, "rpc.method").AsString(); got != "SayHello" {
+		t.Errorf("rpc.method = %q, want SayHello", got)
+	}
+	if ok.Status().Code == otelcodes.Error {
+		t.Errorf("successful call has status %v", ok.Status())
+	}
+
+	if failed.Status().Code != otelcodes.Error || failed.Status().Description != "down" {
+		t.Errorf("failed call status = %v, want error \"down\"", failed.Status())
+	}
+	if got := attr(failed, "rpc.grpc.status_code").AsInt64(); got != int64(codes.Unavailable) {
+		t.Errorf("rpc.grpc.status_code = %v, want %v", got, int64(codes.Unavailable))
+	}
+
+	// The request, then every response.
+	if got := len(streamed.Events()); got != 1+fakeserver.StreamLength {
+		t.Errorf("stream span has %v message events, want %v", got, 1+fakeserver.StreamLength)
+	}
+
+	// Each call carries the context of its own span.
+	calls := srv.Hello.SayHello.Calls()
+	for i, span := range []sdktrace.ReadOnlySpan{ok, failed} {
+		got := calls[i].Metadata.Get("traceparent")
+		want := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+		if len(got) != 1 || got[0] != want {
+			t.Errorf("call %v traceparent = %v, want %v", i+1, got, want)
+		}
+	}
+}
+
+func TestTracingInterceptorsPropagateWithoutExporter(t *testing.T) {
+	srv, client := dialTraced(t, interceptor.TracingOptions{
+		TracerProvider: noop.NewTracerProvider(),
+		Propagator:     propagation.TraceContext{},
+	})
+
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), parent)
+	if _, err := client.SayHello(ctx, &hello.HelloRequest{Name: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := srv.Hello.SayHello.Calls()[0].Metadata.Get("traceparent")
+	want := "00-" + parent.TraceID().String() + "-" + parent.SpanID().String() + "-01"
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("traceparent = %v, want %v", got, want)
+	}
+}
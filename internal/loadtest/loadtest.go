@@ -0,0 +1,233 @@
+// Package loadtest drives a call at a target rate or concurrency and
+// summarizes the outcome.
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/interceptor"
+	"google.golang.org/grpc/status"
+)
+
+// Call is one unit of load, typically one adapter call.
+type Call func(ctx context.Context) error
+
+type Options struct {
+	// Rate is the target number of calls started per second. When zero,
+	// Concurrency workers make calls back to back instead.
+	Rate float64
+
+	// Concurrency is the number of workers without Rate, and caps the
+	// calls in flight with it. Calls due while the cap is reached wait for
+	// a slot, so the achieved rate drops once calls get slower than
+	// Concurrency/Rate.
+	Concurrency int
+
+	Duration time.Duration
+
+	// RampUp raises the rate, or starts the workers, linearly over the
+	// beginning of Duration.
+	RampUp time.Duration
+
+	// Breakers is reported on, if set.
+	Breakers *interceptor.BreakerRegistry
+}
+
+func (o Options) Validate() error {
+	var errs []error
+	if o.Rate < 0 {
+		errs = append(errs, errors.New("rate must not be negative"))
+	}
+	if o.Concurrency < 1 {
+		errs = append(errs, errors.New("concurrency must be at least 1"))
+	}
+	if o.Duration <= 0 {
+		errs = append(errs, errors.New("duration must be positive"))
+	}
+	if o.RampUp < 0 || o.RampUp > o.Duration {
+		errs = append(errs, errors.New("ramp-up must be between 0 and the duration"))
+	}
+	return errors.Join(errs...)
+}
+
+// Run makes calls until opts.Duration elapsed or ctx is done, then waits
+// for the calls in flight. Those are canceled along with the context they
+// get, and only counted as cut short in the report.
+func Run(ctx context.Context, opts Options, call Call) (*Report, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var breakersBefore map[string]int
+	if opts.Breakers != nil {
+		breakersBefore = make(map[string]int)
+		for _, b := range opts.Breakers.Breakers() {
+			breakersBefore[b.Key] = b.Trips
+		}
+	}
+
+	r := &recorder{codes: make(map[string]int)}
+	start := time.Now()
+	runCtx, cancel := context.WithDeadline(ctx, start.Add(opts.Duration))
+	defer cancel()
+
+	if opts.Rate > 0 {
+		runRate(runCtx, opts, call, r)
+	} else {
+		runConcurrency(runCtx, opts, call, r)
+	}
+
+	report := r.report(time.Since(start))
+	report.Target = Target{Rate: opts.Rate, Concurrency: opts.Concurrency, Duration: opts.Duration, RampUp: opts.RampUp}
+	if opts.Breakers != nil {
+		for _, b := range opts.Breakers.Breakers() {
+			report.Breakers = append(report.Breakers, Breaker{
+				Key:   b.Key,
+				State: b.State.String(),
+				Trips: b.Trips - breakersBefore[b.Key],
+			})
+		}
+	}
+	return report, nil
+}
+
+// runRate starts calls at the ramped up rate until ctx is done.
+func runRate(ctx context.Context, opts Options, call Call, r *recorder) {
+	inFlight := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	start := time.Now()
+	for n := 0; ; n++ {
+		t := time.NewTimer(time.Until(start.Add(offset(opts, n))))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case inFlight <- struct{}{}:
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			r.call(ctx, call)
+		}()
+	}
+}
+
+// offset is when call n starts. The rate grows linearly to Rate over
+// RampUp, so n calls are due once the area under the rate reaches n.
+func offset(opts Options, n int) time.Duration {
+	due := float64(n) / opts.Rate
+	ramp := opts.RampUp.Seconds()
+	var seconds float64
+	if due < ramp/2 {
+		seconds = math.Sqrt(2 * ramp * due)
+	} else {
+		seconds = due + ramp/2
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// runConcurrency starts the workers spread over the ramp up, each makes
+// calls back to back until ctx is done.
+func runConcurrency(ctx context.Context, opts Options, call Call, r *recorder) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for i := range opts.Concurrency {
+		delay := opts.RampUp * time.Duration(i) / time.Duration(opts.Concurrency)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t := time.NewTimer(delay)
+			defer t.Stop()
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+			for ctx.Err() == nil {
+				r.call(ctx, call)
+			}
+		}()
+	}
+}
+
+type recorder struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	codes     map[string]int
+	rejected  int
+	cut       int
+	errors    []string
+}
+
+// maxErrorSamples bounds the distinct error messages kept for the report.
+const maxErrorSamples = 10
+
+func (r *recorder) call(ctx context.Context, call Call) {
+	start := time.Now()
+	err := call(ctx)
+	latency := time.Since(start)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// The end of the test failed it, its latency and code say nothing of
+	// the server.
+	if err != nil && ctx.Err() != nil {
+		r.cut++
+		return
+	}
+	r.latencies = append(r.latencies, latency)
+	r.codes[status.Code(err).String()]++
+	if err == nil {
+		return
+	}
+	var open *interceptor.BreakerOpenError
+	if errors.As(err, &open) {
+		r.rejected++
+	}
+	if msg := err.Error(); len(r.errors) < maxErrorSamples && !contains(r.errors, msg) {
+		r.errors = append(r.errors, msg)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *recorder) report(elapsed time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{
+		Calls:             len(r.latencies),
+		Elapsed:           elapsed,
+		Codes:             r.codes,
+		BreakerRejections: r.rejected,
+		CutShort:          r.cut,
+		ErrorSamples:      r.errors,
+		Latency:           summarize(r.latencies),
+	}
+	report.Errors = report.Calls - r.codes["OK"]
+	if elapsed > 0 {
+		report.Throughput = float64(report.Calls) / elapsed.Seconds()
+	}
+	return report
+}
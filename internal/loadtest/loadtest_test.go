@@ -0,0 +1,145 @@
+package loadtest
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestOffset(t *testing.T) {
+	tests := []struct {
+		name   string
+		rate   float64
+		rampUp time.Duration
+		n      int
+		want   time.Duration
+	}{
+		{"first call", 10, 0, 0, 0},
+		{"steady rate", 10, 0, 5, 500 * time.Millisecond},
+		{"first call of a ramp up", 10, 2 * time.Second, 0, 0},
+		// 5 calls are due once rate*t²/(2*rampUp) reaches 5
+		{"during the ramp up", 10, 2 * time.Second, 5, time.Duration(1.41421356 * float64(time.Second))},
+		{"end of the ramp up", 10, 2 * time.Second, 10, 2 * time.Second},
+		{"after the ramp up", 10, 2 * time.Second, 20, 3 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := offset(Options{Rate: tt.rate, RampUp: tt.rampUp}, tt.n)
+			if diff := got - tt.want; diff < -time.Microsecond || diff > time.Microsecond {
+				t.Errorf("offset(%v) = %v, want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+	hundred := make([]time.Duration, 100)
+	for i := range hundred {
+		hundred[i] = ms(i + 1)
+	}
+	rand.Shuffle(len(hundred), func(i, j int) { hundred[i], hundred[j] = hundred[j], hundred[i] })
+
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		want      Latency
+	}{
+		{"none", nil, Latency{}},
+		{"one", []time.Duration{ms(7)}, Latency{ms(7), ms(7), ms(7), ms(7), ms(7), ms(7), ms(7)}},
+		// Nearest rank: p90 of 4 values is the ceil(3.6) = 4th
+		{"four", []time.Duration{ms(40), ms(10), ms(30), ms(20)}, Latency{ms(10), ms(25), ms(20), ms(40), ms(40), ms(40), ms(40)}},
+		{"hundred", hundred, Latency{ms(1), ms(50) + 500*time.Microsecond, ms(50), ms(90), ms(95), ms(99), ms(100)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarize(tt.latencies); got != tt.want {
+				t.Errorf("summarize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCountsCodes(t *testing.T) {
+	var n atomic.Int64
+	call := func(context.Context) error {
+		time.Sleep(time.Millisecond)
+		if n.Add(1)%2 == 0 {
+			return status.Error(codes.Unavailable, "down")
+		}
+		return nil
+	}
+
+	report, err := Run(context.Background(), Options{Concurrency: 2, Duration: 100 * time.Millisecond}, call)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Calls == 0 || report.Codes["OK"]+report.Codes["Unavailable"] != report.Calls {
+		t.Errorf("codes = %v over %v calls, want only OK and Unavailable", report.Codes, report.Calls)
+	}
+	if report.Errors != report.Codes["Unavailable"] {
+		t.Errorf("errors = %v, want the %v Unavailable calls", report.Errors, report.Codes["Unavailable"])
+	}
+	if len(report.ErrorSamples) != 1 {
+		t.Errorf("error samples = %q, want the one distinct message", report.ErrorSamples)
+	}
+}
+
+func TestRunCancelsCallsInFlight(t *testing.T) {
+	// The calls only return once their context is done.
+	call := func(ctx context.Context) error {
+		<-ctx.Done()
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"concurrency", Options{Concurrency: 3, Duration: 50 * time.Millisecond}},
+		{"rate", Options{Rate: 1000, Concurrency: 3, Duration: 50 * time.Millisecond}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done := make(chan *Report)
+			go func() {
+				report, err := Run(context.Background(), tt.opts, call)
+				if err != nil {
+					t.Error(err)
+				}
+				done <- report
+			}()
+
+			select {
+			case report := <-done:
+				if report.Calls != 0 || report.CutShort != 3 {
+					t.Errorf("calls = %v, cut short = %v, want 0 and 3", report.Calls, report.CutShort)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Run did not return once the duration elapsed")
+			}
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		start := time.Now()
+		report, err := Run(ctx, Options{Concurrency: 2, Duration: time.Hour}, call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Run took %v after the context was canceled", elapsed)
+		}
+		if report.CutShort != 2 {
+			t.Errorf("cut short = %v, want 2", report.CutShort)
+		}
+	})
+}
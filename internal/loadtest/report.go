@@ -0,0 +1,182 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"text/tabwriter"
+	"time"
+)
+
+type Report struct {
+	Target     Target        `json:"target"`
+	Calls      int           `json:"calls"`
+	Errors     int           `json:"errors"`
+	Elapsed    time.Duration `json:"-"`
+	Throughput float64       `json:"throughputPerSecond"`
+	Latency    Latency       `json:"latency"`
+
+	// Codes counts the calls by status code name, OK included.
+	Codes map[string]int `json:"codes"`
+
+	// BreakerRejections counts the calls an open circuit breaker failed
+	// without reaching the server. They are part of the Unavailable count.
+	BreakerRejections int       `json:"breakerRejections"`
+	Breakers          []Breaker `json:"breakers,omitempty"`
+
+	// CutShort counts the calls still in flight when the test ended. They
+	// were canceled and are left out of the other counts.
+	CutShort int `json:"cutShort"`
+
+	// ErrorSamples are the first distinct error messages.
+	ErrorSamples []string `json:"errorSamples,omitempty"`
+}
+
+// Target is the load asked for.
+type Target struct {
+	Rate        float64       `json:"rate,omitempty"`
+	Concurrency int           `json:"concurrency"`
+	Duration    time.Duration `json:"-"`
+	RampUp      time.Duration `json:"-"`
+}
+
+func (t Target) MarshalJSON() ([]byte, error) {
+	type plain Target
+	return json.Marshal(struct {
+		plain
+		Duration string `json:"duration"`
+		RampUp   string `json:"rampUp"`
+	}{plain(t), t.Duration.String(), t.RampUp.String()})
+}
+
+// Breaker is a circuit breaker at the end of the test. Trips counts its
+// transitions to open during the test.
+type Breaker struct {
+	Key   string `json:"key"`
+	State string `json:"state"`
+	Trips int    `json:"trips"`
+}
+
+// Latency percentiles use the nearest rank, over failed calls too.
+type Latency struct {
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P95  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// MarshalJSON writes the latencies as fractional milliseconds.
+func (l Latency) MarshalJSON() ([]byte, error) {
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	return json.Marshal(struct {
+		Min  float64 `json:"minMs"`
+		Mean float64 `json:"meanMs"`
+		P50  float64 `json:"p50Ms"`
+		P90  float64 `json:"p90Ms"`
+		P95  float64 `json:"p95Ms"`
+		P99  float64 `json:"p99Ms"`
+		Max  float64 `json:"maxMs"`
+	}{ms(l.Min), ms(l.Mean), ms(l.P50), ms(l.P90), ms(l.P95), ms(l.P99), ms(l.Max)})
+}
+
+func summarize(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		return sorted[max(rank-1, 0)]
+	}
+	return Latency{
+		Min:  sorted[0],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(50),
+		P90:  percentile(90),
+		P95:  percentile(95),
+		P99:  percentile(99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		*Report
+		Elapsed string `json:"elapsed"`
+	}{r, r.Elapsed.String()})
+}
+
+// WriteText writes the report as aligned tables.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "Target:\t%v\n", r.Target)
+	fmt.Fprintf(tw, "Elapsed:\t%v\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(tw, "Calls:\t%v (%.1f/s)\n", r.Calls, r.Throughput)
+	fmt.Fprintf(tw, "Errors:\t%v\n", r.Errors)
+	fmt.Fprintf(tw, "Breaker rejections:\t%v\n", r.BreakerRejections)
+	fmt.Fprintf(tw, "Cut short:\t%v\n", r.CutShort)
+
+	l := r.Latency
+	fmt.Fprintln(tw, "\nLATENCY\tMIN\tMEAN\tP50\tP90\tP95\tP99\tMAX")
+	fmt.Fprintf(tw, "\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+		round(l.Min), round(l.Mean), round(l.P50), round(l.P90), round(l.P95), round(l.P99), round(l.Max))
+
+	fmt.Fprintln(tw, "\nCODE\tCALLS\tSHARE")
+	for _, code := range slices.Sorted(maps.Keys(r.Codes)) {
+		fmt.Fprintf(tw, "%v\t%v\t%.1f%%\n", code, r.Codes[code], 100*float64(r.Codes[code])/float64(r.Calls))
+	}
+
+	if len(r.Breakers) > 0 {
+		fmt.Fprintln(tw, "\nBREAKER\tSTATE\tTRIPS")
+		for _, b := range r.Breakers {
+			fmt.Fprintf(tw, "%v\t%v\t%v\n", b.Key, b.State, b.Trips)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.ErrorSamples) > 0 {
+		fmt.Fprintln(w, "\nErrors:")
+		for _, msg := range r.ErrorSamples {
+			fmt.Fprintf(w, "  %v\n", msg)
+		}
+	}
+	return nil
+}
+
+// round drops the digits that do not matter in a summary.
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
+func (t Target) String() string {
+	mode := fmt.Sprintf("%v workers", t.Concurrency)
+	if t.Rate > 0 {
+		mode = fmt.Sprintf("%v calls/s, at most %v in flight", t.Rate, t.Concurrency)
+	}
+	return fmt.Sprintf("%v for %v, ramp-up %v", mode, t.Duration, t.RampUp)
+}
@@ -0,0 +1,138 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	OutputStderr = "stderr"
+	OutputStdout = "stdout"
+)
+
+type Options struct {
+	// Level is "debug", "info", "warn" or "error".
+	Level string
+
+	// Format is "text" or "json".
+	Format string
+
+	// Output is "stderr", "stdout" or a file path.
+	Output string
+
+	// MaxSize rotates a file output once it grows past that many bytes,
+	// zero disables rotation. MaxBackups old files are kept.
+	MaxSize    int64
+	MaxBackups int
+}
+
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, use debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+func (o Options) Validate() error {
+	var errs []error
+	if _, err := ParseLevel(o.Level); err != nil {
+		errs = append(errs, err)
+	}
+	if o.Format != FormatText && o.Format != FormatJSON {
+		errs = append(errs, fmt.Errorf("unknown log format %q, use %q or %q", o.Format, FormatText, FormatJSON))
+	}
+	if o.Output == "" {
+		errs = append(errs, errors.New("log output is required"))
+	}
+	if o.MaxSize < 0 || o.MaxBackups < 0 {
+		errs = append(errs, errors.New("log rotation sizes must not be negative"))
+	}
+	return errors.Join(errs...)
+}
+
+// New builds the logger described by opts. The returned closer releases the
+// log file, if any.
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var w io.Writer
+	closer := io.Closer(nopCloser{})
+	switch strings.ToLower(opts.Output) {
+	case OutputStderr:
+		w = os.Stderr
+	case OutputStdout:
+		w = os.Stdout
+	default:
+		f, err := OpenRotatingFile(opts.Output, opts.MaxSize, opts.MaxBackups)
+		if err != nil {
+			return nil, nil, err
+		}
+		w, closer = f, f
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch opts.Format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, handlerOpts)
+	default:
+		closer.Close()
+		return nil, nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+	return slog.New(handler), closer, nil
+}
+
+// Setup makes the logger of opts the slog default. Output of the log
+// package goes through it too, at info level.
+func Setup(opts Options) (io.Closer, error) {
+	logger, closer, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(logger)
+	log.SetFlags(0)
+	return closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// MinLevel drops the records of h below level, on top of its own level.
+func MinLevel(h slog.Handler, level slog.Leveler) slog.Handler {
+	return &minLevelHandler{Handler: h, level: level}
+}
+
+type minLevelHandler struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+func (h *minLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.Handler.Enabled(ctx, level)
+}
+
+func (h *minLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &minLevelHandler{Handler: h.Handler.WithAttrs(attrs), level: h.level}
+}
+
+func (h *minLevelHandler) WithGroup(name string) slog.Handler {
+	return &minLevelHandler{Handler: h.Handler.WithGroup(name), level: h.level}
+}
@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewFormats(t *testing.T) {
+	tests := []struct {
+		format string
+		check  func(t *testing.T, out string)
+	}{
+		{
+			format: FormatJSON,
+			check: func(t *testing.T, out string) {
+				var record map[string]any
+				if err := json.Unmarshal([]byte(out), &record); err != nil {
+					t.Fatalf("output %q is not JSON: %v", out, err)
+				}
+				if record["msg"] != "hello" || record["level"] != "INFO" || record["name"] != "a" {
+					t.Errorf("record = %v", record)
+				}
+			},
+		},
+		{
+			format: FormatText,
+			check: func(t *testing.T, out string) {
+				if !strings.Contains(out, "level=INFO msg=hello name=a") {
+					t.Errorf("output = %q, want a text record", out)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "client.log")
+			logger, closer, err := New(Options{Level: "info", Format: tt.format, Output: path})
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Debug("hidden")
+			logger.Info("hello", "name", "a")
+			if err := closer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			out := readFile(t, path)
+			if strings.Contains(out, "hidden") {
+				t.Errorf("debug record written at info level: %q", out)
+			}
+			tt.check(t, strings.TrimSpace(out))
+		})
+	}
+}
+
+func TestNewRotatesFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client.log")
+	logger, closer, err := New(Options{Level: "info", Format: FormatText, Output: path, MaxSize: 100, MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer.Close()
+
+	for range 5 {
+		logger.Info(strings.Repeat("x", 40))
+	}
+	if backup := readFile(t, path+".1"); backup == "" {
+		t.Error("empty backup after rotation")
+	}
+}
+
+func TestNewRejectsUnknownSettings(t *testing.T) {
+	if _, _, err := New(Options{Level: "loud", Format: FormatText, Output: OutputStderr}); err == nil {
+		t.Error("New() accepted an unknown level")
+	}
+	if _, _, err := New(Options{Level: "info", Format: "xml", Output: OutputStderr}); err == nil {
+		t.Error("New() accepted an unknown format")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := Options{Level: "warn", Format: FormatJSON, Output: OutputStdout}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	invalid := Options{Level: "loud", Format: "xml", MaxBackups: -1}
+	err := invalid.Validate()
+	for _, want := range []string{"log level", "log format", "output", "rotation"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want it to mention %q", err, want)
+		}
+	}
+}
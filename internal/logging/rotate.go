@@ -0,0 +1,132 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// RotatingFile is an append only log file that is renamed to path.1, path.2
+// and so on once it grows past maxSize bytes.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu sync.Mutex
+	// file is nil after a rotation failed to reopen it, or once closed.
+	file   *os.File
+	size   int64
+	closed bool
+}
+
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p, rotating the file first when p does not fit. A failed
+// rotation is also printed to stderr, since slog drops the errors of its
+// handlers, and p is still written when the file could be reopened.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	var rotateErr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if rotateErr = f.rotate(); rotateErr != nil {
+			fmt.Fprintf(os.Stderr, "logging: %v\n", rotateErr)
+		}
+		if f.file == nil {
+			return 0, rotateErr
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	var errs []error
+	if err := f.file.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	f.file = nil
+
+	if f.maxBackups == 0 {
+		errs = append(errs, ignoreNotExist(os.Remove(f.path)))
+	} else {
+		errs = append(errs, ignoreNotExist(os.Remove(f.backup(f.maxBackups))))
+		for i := f.maxBackups - 1; i >= 1; i-- {
+			errs = append(errs, ignoreNotExist(os.Rename(f.backup(i), f.backup(i+1))))
+		}
+		errs = append(errs, ignoreNotExist(os.Rename(f.path, f.backup(1))))
+	}
+	if err := errors.Join(errs...); err != nil {
+		errs = []error{fmt.Errorf("rotating log file: %w", err)}
+	}
+
+	// The file is reopened even when the backups could not be moved, to
+	// keep logging.
+	errs = append(errs, f.open())
+	return errors.Join(errs...)
+}
+
+// ignoreNotExist drops the error of a backup that does not exist yet.
+func ignoreNotExist(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (f *RotatingFile) backup(n int) string {
+	return fmt.Sprintf("%v.%v", f.path, n)
+}
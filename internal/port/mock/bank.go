@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/port"
+	"google.golang.org/grpc"
+)
+
+var _ port.BankClientPort = (*BankClient)(nil)
+
+// BankClient records each call, then calls the function set for the
+// method with the same arguments. Methods left unset fail with
+// codes.Unimplemented.
+type BankClient struct {
+	recorder
+
+	GetCurrentBalanceFunc     func(ctx context.Context, in *bank.CurrentBalanceRequest, opts ...grpc.CallOption) (*bank.CurrentBalanceResponse, error)
+	FetchExchangeRatesFunc    func(ctx context.Context, in *bank.ExchangeRateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[bank.ExchangeRateResponse], error)
+	SummarizeTransactionsFunc func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[bank.Transaction, bank.TransactionSummary], error)
+	TransferMultipleFunc      func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[bank.TransferRequest, bank.TransferResponse], error)
+	CreateAccountFunc         func(ctx context.Context, in *bank.AccountRequest, opts ...grpc.CallOption) (*bank.AccountResponse, error)
+}
+
+func (c *BankClient) GetCurrentBalance(ctx context.Context, in *bank.CurrentBalanceRequest, opts ...grpc.CallOption) (*bank.CurrentBalanceResponse, error) {
+	c.record(ctx, "GetCurrentBalance", opts)
+	if c.GetCurrentBalanceFunc == nil {
+		return nil, unimplemented("GetCurrentBalance")
+	}
+	return c.GetCurrentBalanceFunc(ctx, in, opts...)
+}
+
+func (c *BankClient) FetchExchangeRates(ctx context.Context, in *bank.ExchangeRateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[bank.ExchangeRateResponse], error) {
+	c.record(ctx, "FetchExchangeRates", opts)
+	if c.FetchExchangeRatesFunc == nil {
+		return nil, unimplemented("FetchExchangeRates")
+	}
+	return c.FetchExchangeRatesFunc(ctx, in, opts...)
+}
+
+func (c *BankClient) SummarizeTransactions(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[bank.Transaction, bank.TransactionSummary], error) {
+	c.record(ctx, "SummarizeTransactions", opts)
+	if c.SummarizeTransactionsFunc == nil {
+		return nil, unimplemented("SummarizeTransactions")
+	}
+	return c.SummarizeTransactionsFunc(ctx, opts...)
+}
+
+func (c *BankClient) TransferMultiple(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[bank.TransferRequest, bank.TransferResponse], error) {
+	c.record(ctx, "TransferMultiple", opts)
+	if c.TransferMultipleFunc == nil {
+		return nil, unimplemented("TransferMultiple")
+	}
+	return c.TransferMultipleFunc(ctx, opts...)
+}
+
+func (c *BankClient) CreateAccount(ctx context.Context, in *bank.AccountRequest, opts ...grpc.CallOption) (*bank.AccountResponse, error) {
+	c.record(ctx, "CreateAccount", opts)
+	if c.CreateAccountFunc == nil {
+		return nil, unimplemented("CreateAccount")
+	}
+	return c.CreateAccountFunc(ctx, in, opts...)
+}
@@ -0,0 +1,61 @@
+package mock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Call is one call made on a mock client.
+type Call struct {
+	Method string
+	// Deadline is the deadline of the call context, zero without one.
+	Deadline time.Time
+	// Metadata is the outgoing metadata of the call context.
+	Metadata metadata.MD
+	Options  []grpc.CallOption
+}
+
+// recorder keeps the calls made on a mock client.
+type recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+func (r *recorder) record(ctx context.Context, method string, opts []grpc.CallOption) {
+	call := Call{Method: method, Options: opts}
+	call.Deadline, _ = ctx.Deadline()
+	call.Metadata, _ = metadata.FromOutgoingContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+// Calls returns the calls made so far, oldest first.
+func (r *recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// SetHeader fills the grpc.Header options among opts with md, the way the
+// transport does once the server sent its headers.
+func SetHeader(opts []grpc.CallOption, md metadata.MD) {
+	for _, opt := range opts {
+		if o, ok := opt.(grpc.HeaderCallOption); ok {
+			*o.HeaderAddr = md
+		}
+	}
+}
+
+// SetTrailer fills the grpc.Trailer options among opts with md.
+func SetTrailer(opts []grpc.CallOption, md metadata.MD) {
+	for _, opt := range opts {
+		if o, ok := opt.(grpc.TrailerCallOption); ok {
+			*o.TrailerAddr = md
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/grpc-client/internal/port"
+	"google.golang.org/grpc"
+)
+
+var _ port.HelloClientPort = (*HelloClient)(nil)
+
+// HelloClient records each call, then calls the function set for the
+// method with the same arguments. Methods left unset fail with
+// codes.Unimplemented.
+type HelloClient struct {
+	recorder
+
+	SayHelloFunc          func(ctx context.Context, in *hello.HelloRequest, opts ...grpc.CallOption) (*hello.HelloResponse, error)
+	HelloServerStreamFunc func(ctx context.Context, in *hello.HelloRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[hello.HelloResponse], error)
+	HelloClientStreamFunc func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[hello.HelloRequest, hello.HelloResponse], error)
+	HelloContinuousFunc   func(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[hello.HelloRequest, hello.HelloResponse], error)
+}
+
+func (c *HelloClient) SayHello(ctx context.Context, in *hello.HelloRequest, opts ...grpc.CallOption) (*hello.HelloResponse, error) {
+	c.record(ctx, "SayHello", opts)
+	if c.SayHelloFunc == nil {
+		return nil, unimplemented("SayHello")
+	}
+	return c.SayHelloFunc(ctx, in, opts...)
+}
+
+func (c *HelloClient) HelloServerStream(ctx context.Context, in *hello.HelloRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[hello.HelloResponse], error) {
+	c.record(ctx, "HelloServerStream", opts)
+	if c.HelloServerStreamFunc == nil {
+		return nil, unimplemented("HelloServerStream")
+	}
+	return c.HelloServerStreamFunc(ctx, in, opts...)
+}
+
+func (c *HelloClient) HelloClientStream(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[hello.HelloRequest, hello.HelloResponse], error) {
+	c.record(ctx, "HelloClientStream", opts)
+	if c.HelloClientStreamFunc == nil {
+		return nil, unimplemented("HelloClientStream")
+	}
+	return c.HelloClientStreamFunc(ctx, opts...)
+}
+
+func (c *HelloClient) HelloContinuous(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[hello.HelloRequest, hello.HelloResponse], error) {
+	c.record(ctx, "HelloContinuous", opts)
+	if c.HelloContinuousFunc == nil {
+		return nil, unimplemented("HelloContinuous")
+	}
+	return c.HelloContinuousFunc(ctx, opts...)
+}
@@ -0,0 +1,102 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/resiliency"
+	"github.com/VallabhSLEPAM/grpc-client/internal/port"
+	"google.golang.org/grpc"
+)
+
+var (
+	_ port.ResiliencyClientPort         = (*ResiliencyClient)(nil)
+	_ port.ResiliencyMetadataClientPort = (*ResiliencyMetadataClient)(nil)
+)
+
+// ResiliencyClient records each call, then calls the function set for the
+// method with the same arguments. Methods left unset fail with
+// codes.Unimplemented.
+type ResiliencyClient struct {
+	recorder
+
+	UnaryResiliencyFunc         func(ctx context.Context, in *resiliency.ResiliencyRequest, opts ...grpc.CallOption) (*resiliency.ResiliencyResponse, error)
+	ServerResiliencyFunc        func(ctx context.Context, in *resiliency.ResiliencyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[resiliency.ResiliencyResponse], error)
+	ClientResiliencyFunc        func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse], error)
+	BiDirectionalResiliencyFunc func(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse], error)
+}
+
+func (c *ResiliencyClient) UnaryResiliency(ctx context.Context, in *resiliency.ResiliencyRequest, opts ...grpc.CallOption) (*resiliency.ResiliencyResponse, error) {
+	c.record(ctx, "UnaryResiliency", opts)
+	if c.UnaryResiliencyFunc == nil {
+		return nil, unimplemented("UnaryResiliency")
+	}
+	return c.UnaryResiliencyFunc(ctx, in, opts...)
+}
+
+func (c *ResiliencyClient) ServerResiliency(ctx context.Context, in *resiliency.ResiliencyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[resiliency.ResiliencyResponse], error) {
+	c.record(ctx, "ServerResiliency", opts)
+	if c.ServerResiliencyFunc == nil {
+		return nil, unimplemented("ServerResiliency")
+	}
+	return c.ServerResiliencyFunc(ctx, in, opts...)
+}
+
+func (c *ResiliencyClient) ClientResiliency(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse], error) {
+	c.record(ctx, "ClientResiliency", opts)
+	if c.ClientResiliencyFunc == nil {
+		return nil, unimplemented("ClientResiliency")
+	}
+	return c.ClientResiliencyFunc(ctx, opts...)
+}
+
+func (c *ResiliencyClient) BiDirectionalResiliency(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse], error) {
+	c.record(ctx, "BiDirectionalResiliency", opts)
+	if c.BiDirectionalResiliencyFunc == nil {
+		return nil, unimplemented("BiDirectionalResiliency")
+	}
+	return c.BiDirectionalResiliencyFunc(ctx, opts...)
+}
+
+// ResiliencyMetadataClient records each call, then calls the function set
+// for the method with the same arguments. Methods left unset fail with
+// codes.Unimplemented.
+type ResiliencyMetadataClient struct {
+	recorder
+
+	UnaryResiliencyWithMetadataFunc         func(ctx context.Context, in *resiliency.ResiliencyRequest, opts ...grpc.CallOption) (*resiliency.ResiliencyResponse, error)
+	ServerResiliencyWithMetadataFunc        func(ctx context.Context, in *resiliency.ResiliencyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[resiliency.ResiliencyResponse], error)
+	ClientResiliencyWithMetadataFunc        func(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse], error)
+	BiDirectionalResiliencyWithMetadataFunc func(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse], error)
+}
+
+func (c *ResiliencyMetadataClient) UnaryResiliencyWithMetadata(ctx context.Context, in *resiliency.ResiliencyRequest, opts ...grpc.CallOption) (*resiliency.ResiliencyResponse, error) {
+	c.record(ctx, "UnaryResiliencyWithMetadata", opts)
+	if c.UnaryResiliencyWithMetadataFunc == nil {
+		return nil, unimplemented("UnaryResiliencyWithMetadata")
+	}
+	return c.UnaryResiliencyWithMetadataFunc(ctx, in, opts...)
+}
+
+func (c *ResiliencyMetadataClient) ServerResiliencyWithMetadata(ctx context.Context, in *resiliency.ResiliencyRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[resiliency.ResiliencyResponse], error) {
+	c.record(ctx, "ServerResiliencyWithMetadata", opts)
+	if c.ServerResiliencyWithMetadataFunc == nil {
+		return nil, unimplemented("ServerResiliencyWithMetadata")
+	}
+	return c.ServerResiliencyWithMetadataFunc(ctx, in, opts...)
+}
+
+func (c *ResiliencyMetadataClient) ClientResiliencyWithMetadata(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse], error) {
+	c.record(ctx, "ClientResiliencyWithMetadata", opts)
+	if c.ClientResiliencyWithMetadataFunc == nil {
+		return nil, unimplemented("ClientResiliencyWithMetadata")
+	}
+	return c.ClientResiliencyWithMetadataFunc(ctx, opts...)
+}
+
+func (c *ResiliencyMetadataClient) BiDirectionalResiliencyWithMetadata(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse], error) {
+	c.record(ctx, "BiDirectionalResiliencyWithMetadata", opts)
+	if c.BiDirectionalResiliencyWithMetadataFunc == nil {
+		return nil, unimplemented("BiDirectionalResiliencyWithMetadata")
+	}
+	return c.BiDirectionalResiliencyWithMetadataFunc(ctx, opts...)
+}
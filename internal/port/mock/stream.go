@@ -0,0 +1,184 @@
+// Package mock holds hand-written implementations of the client ports and
+// of the stream clients they return, so adapters can be used without a
+// network.
+package mock
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func unimplemented(method string) error {
+	return status.Errorf(codes.Unimplemented, "mock: %v is not set", method)
+}
+
+// stream implements the parts of grpc.ClientStream shared by the fakes.
+type stream struct {
+	// Ctx defaults to context.Background().
+	Ctx context.Context
+
+	HeaderMD  metadata.MD
+	HeaderErr error
+	TrailerMD metadata.MD
+
+	mu     sync.Mutex
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (s *stream) Header() (metadata.MD, error) {
+	return s.HeaderMD, s.HeaderErr
+}
+
+func (s *stream) Trailer() metadata.MD {
+	return s.TrailerMD
+}
+
+func (s *stream) Context() context.Context {
+	if s.Ctx == nil {
+		return context.Background()
+	}
+	return s.Ctx
+}
+
+func (s *stream) done() chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed == nil {
+		s.closed = make(chan struct{})
+	}
+	return s.closed
+}
+
+func (s *stream) CloseSend() error {
+	done := s.done()
+	s.once.Do(func() { close(done) })
+	return nil
+}
+
+// SendMsg and RecvMsg are left out, the adapters use the typed Send and
+// Recv.
+func (s *stream) SendMsg(m any) error {
+	return nil
+}
+
+func (s *stream) RecvMsg(m any) error {
+	return io.EOF
+}
+
+// ServerStream replays Responses, then returns Err, io.EOF when nil.
+type ServerStream[Resp any] struct {
+	stream
+
+	Responses []*Resp
+	Err       error
+
+	recv int
+}
+
+func (s *ServerStream[Resp]) Recv() (*Resp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.recv < len(s.Responses) {
+		s.recv++
+		return s.Responses[s.recv-1], nil
+	}
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return nil, io.EOF
+}
+
+// ClientStream records the requests sent and answers CloseAndRecv with
+// Response or Err. Once SendErr is set Send fails with it, the way a stream
+// fails after the server ended it, typically with io.EOF.
+type ClientStream[Req, Resp any] struct {
+	stream
+
+	Response *Resp
+	Err      error
+	SendErr  error
+
+	sent []*Req
+}
+
+func (s *ClientStream[Req, Resp]) Send(req *Req) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.SendErr != nil {
+		return s.SendErr
+	}
+	s.sent = append(s.sent, req)
+	return nil
+}
+
+// Sent returns the requests sent so far.
+func (s *ClientStream[Req, Resp]) Sent() []*Req {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Req(nil), s.sent...)
+}
+
+func (s *ClientStream[Req, Resp]) CloseAndRecv() (*Resp, error) {
+	s.CloseSend()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return s.Response, nil
+}
+
+// BidiStream records the requests sent and replays Responses. Once they
+// ran out Recv returns Err if set. Otherwise it waits for CloseSend, or the
+// end of Ctx, before returning io.EOF, so the requests sent concurrently are
+// all recorded by then.
+type BidiStream[Req, Resp any] struct {
+	stream
+
+	Responses []*Resp
+	Err       error
+	SendErr   error
+
+	sent []*Req
+	recv int
+}
+
+func (s *BidiStream[Req, Resp]) Send(req *Req) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.SendErr != nil {
+		return s.SendErr
+	}
+	s.sent = append(s.sent, req)
+	return nil
+}
+
+func (s *BidiStream[Req, Resp]) Sent() []*Req {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Req(nil), s.sent...)
+}
+
+func (s *BidiStream[Req, Resp]) Recv() (*Resp, error) {
+	s.mu.Lock()
+	if s.recv < len(s.Responses) {
+		s.recv++
+		defer s.mu.Unlock()
+		return s.Responses[s.recv-1], nil
+	}
+	s.mu.Unlock()
+
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	select {
+	case <-s.done():
+		return nil, io.EOF
+	case <-s.Context().Done():
+		return nil, status.FromContextError(s.Context().Err()).Err()
+	}
+}
@@ -0,0 +1,149 @@
+package recording
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Recorder appends every exchange to its file as soon as the call finished,
+// one JSON object per line after the version header, so a crash only loses
+// the calls still running. Calls that never finished are left out.
+type Recorder struct {
+	file     *os.File
+	redactor *redact.Redactor
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+}
+
+// Create truncates path and writes the version header right away, so a bad
+// path fails before any call. Messages, metadata and statuses are stored as
+// redacted by redactor, as is when nil.
+func Create(path string, redactor *redact.Redactor) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create recording: %w", err)
+	}
+	if err := json.NewEncoder(f).Encode(File{Version: FormatVersion}); err != nil {
+		return nil, fmt.Errorf("create recording: %w", errors.Join(err, f.Close()))
+	}
+	return &Recorder{file: f, redactor: redactor}, nil
+}
+
+// Begin starts recording a call. md is the outgoing metadata.
+func (r *Recorder) Begin(method string, kind Kind, md metadata.MD) *Exchange {
+	e := &Exchange{Method: method, Kind: kind, StartedAt: time.Now(), redactor: r.redactor, recorder: r}
+	e.Metadata = e.redactMetadata(md)
+	return e
+}
+
+// write appends a finished exchange. The first error is kept for Close.
+func (r *Recorder) write(e *Exchange) {
+	data, err := json.Marshal(e)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.err != nil {
+		return
+	}
+	if err == nil {
+		_, err = r.file.Write(append(data, '\n'))
+	}
+	if err != nil {
+		r.err = fmt.Errorf("write recording: %w", err)
+	}
+}
+
+// Close closes the file. It returns the first error writing an exchange, the
+// calls finishing afterwards are not recorded.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return errors.Join(r.err, r.file.Close())
+}
+
+// Replayer serves the exchanges of a recording, for each method in the
+// order they were recorded.
+type Replayer struct {
+	mu     sync.Mutex
+	queues map[string][]*Exchange
+}
+
+// Load reads a recording. A last line cut short, as left by a recorder that
+// crashed mid write, is ignored. Version 1 recordings, a single object with
+// all exchanges, are still read.
+func Load(path string) (*Replayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("load recording: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	var file File
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("load recording %v: %w", path, err)
+	}
+	switch file.Version {
+	case 1:
+	case FormatVersion:
+		for {
+			var e Exchange
+			err := dec.Decode(&e)
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("load recording %v: %w", path, err)
+			}
+			file.Exchanges = append(file.Exchanges, &e)
+		}
+	default:
+		return nil, fmt.Errorf("load recording %v: unsupported version %d", path, file.Version)
+	}
+
+	r := &Replayer{queues: make(map[string][]*Exchange)}
+	for _, e := range file.Exchanges {
+		e.done = true
+		r.queues[e.Method] = append(r.queues[e.Method], e)
+	}
+	return r, nil
+}
+
+// Next returns the next exchange of method. Once they ran out it fails with
+// codes.Unavailable, as if there was no server.
+func (r *Replayer) Next(method string) (*Exchange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	queue := r.queues[method]
+	if len(queue) == 0 {
+		return nil, status.Errorf(codes.Unavailable, "replay: no recorded exchange left for %v", method)
+	}
+	r.queues[method] = queue[1:]
+	return queue[0], nil
+}
+
+// Remaining returns how many exchanges were not replayed yet.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, queue := range r.queues {
+		n += len(queue)
+	}
+	return n
+}
@@ -0,0 +1,220 @@
+// Package recording stores RPC exchanges in golden files and serves them
+// back, see the record and replay interceptors of package interceptor.
+//
+// Messages are stored as protojson, after the recorder's redactor masked
+// account numbers and the like, so replayed payloads carry the masked
+// values. Without a redactor recordings hold the payloads as sent and
+// received and should not be shared as is.
+package recording
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	// Registers the error details, protojson needs them to encode statuses.
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// FormatVersion is written to every recording.
+const FormatVersion = 2
+
+type Kind string
+
+const (
+	KindUnary        Kind = "unary"
+	KindServerStream Kind = "serverStream"
+	KindClientStream Kind = "clientStream"
+	KindBidiStream   Kind = "bidiStream"
+)
+
+func StreamKind(desc *grpc.StreamDesc) Kind {
+	switch {
+	case desc.ClientStreams && desc.ServerStreams:
+		return KindBidiStream
+	case desc.ClientStreams:
+		return KindClientStream
+	default:
+		return KindServerStream
+	}
+}
+
+// File is the first line of a recording, the exchanges follow one per line.
+// Only version 1 recordings hold them in Exchanges.
+type File struct {
+	Version   int         `json:"version"`
+	Exchanges []*Exchange `json:"exchanges,omitempty"`
+}
+
+// Exchange is one call. Status is the google.rpc.Status of the call, it is
+// left out when the call succeeded.
+type Exchange struct {
+	Method    string              `json:"method"`
+	Kind      Kind                `json:"kind"`
+	Metadata  map[string][]string `json:"metadata,omitempty"`
+	Requests  []json.RawMessage   `json:"requests,omitempty"`
+	Header    map[string][]string `json:"header,omitempty"`
+	Responses []json.RawMessage   `json:"responses,omitempty"`
+	Trailer   map[string][]string `json:"trailer,omitempty"`
+	Status    json.RawMessage     `json:"status,omitempty"`
+	StartedAt time.Time           `json:"startedAt"`
+	Duration  string              `json:"duration"`
+
+	mu       sync.Mutex
+	done     bool
+	redactor *redact.Redactor
+	recorder *Recorder
+}
+
+func marshal(m any) (json.RawMessage, error) {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("recording: %T is not a proto message", m)
+	}
+	return protojson.Marshal(msg)
+}
+
+func unmarshal(data json.RawMessage, m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return fmt.Errorf("recording: %T is not a proto message", m)
+	}
+	return protojson.Unmarshal(data, msg)
+}
+
+// redactMessage returns m as it is to be stored.
+func (e *Exchange) redactMessage(m any) any {
+	msg, ok := m.(proto.Message)
+	if e.redactor == nil || !ok {
+		return m
+	}
+	return e.redactor.Proto(msg)
+}
+
+func (e *Exchange) redactMetadata(md metadata.MD) metadata.MD {
+	if e.redactor == nil || md == nil {
+		return md
+	}
+	redacted := make(metadata.MD, len(md))
+	for k, vs := range md {
+		for _, v := range vs {
+			redacted[k] = append(redacted[k], e.redactor.KeyValue(k, v))
+		}
+	}
+	return redacted
+}
+
+// redactStatus redacts the message and the details of st. Details of types
+// that are not linked in are kept as is.
+func (e *Exchange) redactStatus(st *spb.Status) *spb.Status {
+	if e.redactor == nil {
+		return st
+	}
+	st = proto.Clone(st).(*spb.Status)
+	st.Message = e.redactor.String(st.Message)
+	for i, detail := range st.Details {
+		m, err := detail.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+		if packed, err := anypb.New(e.redactor.Proto(m)); err == nil {
+			st.Details[i] = packed
+		}
+	}
+	return st
+}
+
+func (e *Exchange) AddRequest(m any) error {
+	data, err := marshal(e.redactMessage(m))
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Requests = append(e.Requests, data)
+	return nil
+}
+
+func (e *Exchange) AddResponse(m any) error {
+	data, err := marshal(e.redactMessage(m))
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.Responses = append(e.Responses, data)
+	return nil
+}
+
+// Finish records the outcome of the call and has the recorder write the
+// exchange. err is the error the call ended with, io.EOF counting as
+// success.
+func (e *Exchange) Finish(header, trailer metadata.MD, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.done {
+		return
+	}
+	if e.recorder != nil {
+		defer e.recorder.write(e)
+	}
+	e.done = true
+	e.Duration = time.Since(e.StartedAt).String()
+	e.Header = e.redactMetadata(header)
+	e.Trailer = e.redactMetadata(trailer)
+	if st := status.Convert(err); err != nil && !errors.Is(err, io.EOF) {
+		// Marshaling a status only fails on broken details, drop them then
+		if data, err := protojson.Marshal(e.redactStatus(st.Proto())); err == nil {
+			e.Status = data
+		} else {
+			e.Status, _ = protojson.Marshal(&spb.Status{Code: int32(st.Code()), Message: e.redactStatus(st.Proto()).GetMessage()})
+		}
+	}
+}
+
+// Request decodes the i-th request into m.
+func (e *Exchange) Request(i int, m any) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if i >= len(e.Requests) {
+		return fmt.Errorf("recording: %v has %d requests", e.Method, len(e.Requests))
+	}
+	return unmarshal(e.Requests[i], m)
+}
+
+// Response decodes the i-th response into m. ok is false when there are no
+// more responses.
+func (e *Exchange) Response(i int, m any) (ok bool, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if i >= len(e.Responses) {
+		return false, nil
+	}
+	return true, unmarshal(e.Responses[i], m)
+}
+
+// Err returns the status error of the call, nil when it succeeded.
+func (e *Exchange) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.Status) == 0 {
+		return nil
+	}
+	var st spb.Status
+	if err := protojson.Unmarshal(e.Status, &st); err != nil {
+		return status.Errorf(codes.Internal, "recording: %v", err)
+	}
+	return status.ErrorProto(&st)
+}
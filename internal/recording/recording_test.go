@@ -0,0 +1,165 @@
+package recording_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/recording"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestRecorderRedacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.json")
+	rec, err := recording.Create(path, redact.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const method = "/bank.BankService/TransferMultiple"
+	e := rec.Begin(method, recording.KindClientStream, metadata.Pairs("account-number", "79927398713"))
+	req := &bank.TransferRequest{FromAccountNumber: "79927398713", ToAccountNumber: "87654321", Current: "USD", Amount: 60}
+	if err := e.AddRequest(req); err != nil {
+		t.Fatal(err)
+	}
+	st, err := status.New(codes.FailedPrecondition, "account 79927398713 lacks funds").WithDetails(&errdetails.PreconditionFailure{
+		Violations: []*errdetails.PreconditionFailure_Violation{{Type: "INSUFFICIENT_FUNDS", Subject: "79927398713"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Finish(nil, metadata.Pairs("account-number", "79927398713"), st.Err())
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "79927398713") || strings.Contains(string(data), "87654321") {
+		t.Errorf("recording holds an account number:\n%s", data)
+	}
+
+	replayer, err := recording.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := replayer.Next(method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recorded bank.TransferRequest
+	if err := got.Request(0, &recorded); err != nil {
+		t.Fatal(err)
+	}
+	if recorded.GetFromAccountNumber() != "*******8713" || recorded.GetAmount() != 0 || recorded.GetCurrent() != "USD" {
+		t.Errorf("recorded request = %v, want the account masked, the amount cleared and the currency kept", &recorded)
+	}
+	if !proto.Equal(&recorded, redact.Default().Proto(req)) {
+		t.Errorf("recorded request = %v, want the redacted request", &recorded)
+	}
+
+	replayed := status.Convert(got.Err())
+	if replayed.Code() != codes.FailedPrecondition || replayed.Message() != "account *******8713 lacks funds" {
+		t.Errorf("replayed status = %v, want FailedPrecondition with a masked message", replayed)
+	}
+	var subject string
+	for _, d := range replayed.Details() {
+		if pf, ok := d.(*errdetails.PreconditionFailure); ok {
+			subject = pf.GetViolations()[0].GetSubject()
+		}
+	}
+	if subject != "*******8713" {
+		t.Errorf("replayed violation subject = %q, want *******8713", subject)
+	}
+}
+
+func TestRecorderWithoutRedactor(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.json")
+	rec, err := recording.Create(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := rec.Begin("/bank.BankService/GetCurrentBalance", recording.KindUnary, nil)
+	if err := e.AddRequest(&bank.CurrentBalanceRequest{AccountNumber: "79927398713"}); err != nil {
+		t.Fatal(err)
+	}
+	e.Finish(nil, nil, nil)
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "79927398713") {
+		t.Errorf("recording without a redactor lost the account number:\n%s", data)
+	}
+}
+
+func TestRecorderWritesEachExchangeWhenItFinishes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.json")
+	rec, err := recording.Create(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Close()
+
+	const method = "/bank.BankService/GetCurrentBalance"
+	rec.Begin(method, recording.KindUnary, nil)
+	e := rec.Begin(method, recording.KindUnary, nil)
+	if err := e.AddResponse(&bank.CurrentBalanceResponse{Amount: 42}); err != nil {
+		t.Fatal(err)
+	}
+	e.Finish(nil, nil, nil)
+
+	// A recorder killed mid write leaves a line cut short.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"method":"/bank.BankService/GetCurr`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	replayer, err := recording.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := replayer.Remaining(); n != 1 {
+		t.Fatalf("recording holds %d exchanges before Close, want the finished one", n)
+	}
+	got, err := replayer.Next(method)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp bank.CurrentBalanceResponse
+	if ok, err := got.Response(0, &resp); !ok || err != nil || resp.GetAmount() != 42 {
+		t.Errorf("Response(0) = %v, %v, %v, want the recorded response", &resp, ok, err)
+	}
+}
+
+func TestLoadVersion1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calls.json")
+	data := `{"version": 1, "exchanges": [{"method": "/bank.BankService/GetCurrentBalance", "kind": "unary", "responses": [{"amount": 42}]}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	replayer, err := recording.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := replayer.Remaining(); n != 1 {
+		t.Errorf("Remaining() = %d, want 1", n)
+	}
+}
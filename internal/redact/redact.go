@@ -0,0 +1,352 @@
+package redact
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Mask replaces the value of a redacted field.
+const Mask = "[REDACTED]"
+
+const (
+	// ActionMask replaces the whole value with Mask.
+	ActionMask = "mask"
+
+	// ActionPartial keeps the last four characters of strings, e.g.
+	// "******7001". Other values are masked.
+	ActionPartial = "partial"
+
+	// ActionDrop leaves the field out of logged messages altogether.
+	ActionDrop = "drop"
+)
+
+// Rule selects what to redact. A rule either matches fields or free text.
+type Rule struct {
+	// Field is a proto field name such as "account_number", a full name
+	// such as "bank.TransferRequest.amount" or a path.Match pattern of
+	// either. Metadata keys are matched too, ignoring case, dashes and
+	// underscores.
+	Field string
+
+	// Pattern is a regular expression applied to free text: error
+	// messages, metadata values and string fields no field rule matched.
+	Pattern string
+
+	// Luhn only redacts the matches of Pattern whose digits pass the Luhn
+	// checksum, as card and most account numbers do, so that dates,
+	// timestamps and order IDs stay readable.
+	Luhn bool
+
+	// Action defaults to ActionMask.
+	Action string
+}
+
+// DefaultRules cover the account numbers, names and amounts of the bank
+// service.
+var DefaultRules = []Rule{
+	{Field: "*account_number", Action: ActionPartial},
+	{Field: "account_uuid", Action: ActionPartial},
+	{Field: "account_name"},
+	{Field: "amount"},
+	{Field: "initial_deposit_amount"},
+	{Field: "sum_amount_*"},
+	{Field: "sum_total"},
+	// Account numbers are at least 8 digits
+	{Pattern: `\b\d{8,19}\b`, Luhn: true, Action: ActionPartial},
+}
+
+type pattern struct {
+	re     *regexp.Regexp
+	luhn   bool
+	action string
+}
+
+// Redactor masks proto fields, metadata and free text according to its
+// rules. The zero value redacts nothing.
+type Redactor struct {
+	fields   []Rule
+	patterns []pattern
+}
+
+// New compiles rules, it fails on an invalid pattern or action.
+func New(rules ...Rule) (*Redactor, error) {
+	r := &Redactor{}
+	for _, rule := range rules {
+		switch rule.Action {
+		case "":
+			rule.Action = ActionMask
+		case ActionMask, ActionPartial, ActionDrop:
+		default:
+			return nil, fmt.Errorf("unknown redaction action %q", rule.Action)
+		}
+
+		switch {
+		case rule.Field != "" && rule.Pattern != "":
+			return nil, fmt.Errorf("redaction rule %q sets both a field and a pattern", rule.Field)
+		case rule.Field != "" && rule.Luhn:
+			return nil, fmt.Errorf("redaction rule %q: luhn only applies to patterns", rule.Field)
+		case rule.Field != "":
+			if _, err := path.Match(rule.Field, ""); err != nil {
+				return nil, fmt.Errorf("redaction field %q: %w", rule.Field, err)
+			}
+			r.fields = append(r.fields, rule)
+		case rule.Pattern != "":
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("redaction pattern %q: %w", rule.Pattern, err)
+			}
+			r.patterns = append(r.patterns, pattern{re: re, luhn: rule.Luhn, action: rule.Action})
+		default:
+			return nil, fmt.Errorf("redaction rule needs a field or a pattern")
+		}
+	}
+	return r, nil
+}
+
+var (
+	defaultRedactor atomic.Pointer[Redactor]
+
+	// builtin applies DefaultRules.
+	builtin *Redactor
+)
+
+func init() {
+	r, err := New(DefaultRules...)
+	if err != nil {
+		panic(err)
+	}
+	builtin = r
+	defaultRedactor.Store(r)
+}
+
+// Default returns the redactor used by the adapters and interceptors unless
+// they are given one.
+func Default() *Redactor {
+	return defaultRedactor.Load()
+}
+
+// SetDefault replaces the redactor returned by Default. A nil r restores the
+// one of DefaultRules.
+func SetDefault(r *Redactor) {
+	if r == nil {
+		r = builtin
+	}
+	defaultRedactor.Store(r)
+}
+
+// String redacts free text such as an error message.
+func (r *Redactor) String(s string) string {
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			if p.luhn && !luhnValid(match) {
+				return match
+			}
+			return apply(p.action, match).(string)
+		})
+	}
+	return s
+}
+
+// Error returns the redacted message of err, or "" for a nil error.
+func (r *Redactor) Error(err error) string {
+	if err == nil {
+		return ""
+	}
+	return r.String(err.Error())
+}
+
+// KeyValue redacts the value of a metadata entry, such as the ErrorInfo
+// metadata sent by the server.
+func (r *Redactor) KeyValue(key, value string) string {
+	if rule, ok := r.fieldRule(key, ""); ok {
+		if rule.Action == ActionDrop {
+			return Mask
+		}
+		return apply(rule.Action, value).(string)
+	}
+	return r.String(value)
+}
+
+// Message returns a loggable copy of m, a map keyed by field name, with the
+// redacted fields masked. Values other than proto messages are returned as
+// is.
+func (r *Redactor) Message(m any) any {
+	msg, ok := m.(proto.Message)
+	if !ok || msg == nil {
+		return m
+	}
+	return r.message(msg.ProtoReflect())
+}
+
+func (r *Redactor) message(m protoreflect.Message) map[string]any {
+	result := make(map[string]any)
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+		rule, ok := r.fieldRule(name, string(fd.FullName()))
+		switch {
+		case !ok:
+			result[name] = r.value(fd, v)
+		case rule.Action == ActionDrop:
+		case fd.IsList() || fd.IsMap():
+			result[name] = Mask
+		default:
+			result[name] = apply(rule.Action, r.single(fd, v))
+		}
+		return true
+	})
+	return result
+}
+
+// Proto returns a copy of m with the redacted fields masked, which unlike
+// Message can still be encoded as m. Strings are redacted the same way,
+// other redacted fields are cleared.
+func (r *Redactor) Proto(m proto.Message) proto.Message {
+	if m == nil {
+		return nil
+	}
+	c := proto.Clone(m)
+	r.redactProto(c.ProtoReflect())
+	return c
+}
+
+func (r *Redactor) redactProto(m protoreflect.Message) {
+	// Collect first, fields are not to be set while ranging over them.
+	var fields []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+	for _, fd := range fields {
+		rule, ok := r.fieldRule(string(fd.Name()), string(fd.FullName()))
+		switch {
+		case ok && rule.Action != ActionDrop && fd.Kind() == protoreflect.StringKind && fd.Cardinality() != protoreflect.Repeated:
+			m.Set(fd, protoreflect.ValueOfString(apply(rule.Action, m.Get(fd).String()).(string)))
+		case ok:
+			m.Clear(fd)
+		case fd.IsList():
+			list := m.Mutable(fd).List()
+			for i := 0; i < list.Len(); i++ {
+				list.Set(i, r.redactProtoValue(fd, list.Get(i)))
+			}
+		case fd.IsMap():
+			entries := m.Mutable(fd).Map()
+			entries.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				entries.Set(k, r.redactProtoValue(fd.MapValue(), v))
+				return true
+			})
+		default:
+			m.Set(fd, r.redactProtoValue(fd, m.Get(fd)))
+		}
+	}
+}
+
+func (r *Redactor) redactProtoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) protoreflect.Value {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		r.redactProto(v.Message())
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(r.String(v.String()))
+	}
+	return v
+}
+
+func (r *Redactor) value(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		items := make([]any, list.Len())
+		for i := range items {
+			items[i] = r.single(fd, list.Get(i))
+		}
+		return items
+	case fd.IsMap():
+		entries := make(map[string]any)
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			entries[k.String()] = r.single(fd.MapValue(), v)
+			return true
+		})
+		return entries
+	}
+	return r.single(fd, v)
+}
+
+func (r *Redactor) single(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return r.message(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	case protoreflect.BytesKind:
+		return len(v.Bytes())
+	case protoreflect.StringKind:
+		return r.String(v.String())
+	}
+	return v.Interface()
+}
+
+func (r *Redactor) fieldRule(name, fullName string) (Rule, bool) {
+	normalized := normalize(name)
+	for _, rule := range r.fields {
+		if matched, _ := path.Match(rule.Field, name); matched {
+			return rule, true
+		}
+		if fullName != "" {
+			if matched, _ := path.Match(rule.Field, fullName); matched {
+				return rule, true
+			}
+		}
+		// Metadata keys come as "fromAccountNumber" or "from-account-number"
+		if matched, _ := path.Match(normalize(rule.Field), normalized); matched {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func normalize(s string) string {
+	s = strings.ToLower(s)
+	return strings.NewReplacer("_", "", "-", "").Replace(s)
+}
+
+func apply(action string, v any) any {
+	s, ok := v.(string)
+	if action != ActionPartial || !ok {
+		return Mask
+	}
+	runes := []rune(s)
+	if len(runes) <= 4 {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
+
+// luhnValid reports whether the digits of s, which needs at least two,
+// pass the Luhn checksum. Other characters are skipped.
+func luhnValid(s string) bool {
+	var sum, digits int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 2 && sum%10 == 0
+}
@@ -0,0 +1,143 @@
+package redact_test
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	"github.com/VallabhSLEPAM/grpc-client/internal/redact"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDefaultString(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "account 4111111111111111 not found", want: "account ************1111 not found"},
+		{in: "account 79927398713 not found", want: "account *******8713 not found"},
+		{in: "statement of 20261018", want: "statement of 20261018"},
+		{in: "sent at 1760781600", want: "sent at 1760781600"},
+		{in: "sent at 1760781601000", want: "sent at 1760781601000"},
+		{in: "order 12345678 shipped", want: "order 12345678 shipped"},
+		{in: "short 1234567", want: "short 1234567"},
+	}
+	for _, tt := range tests {
+		if got := redact.Default().String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPartialKeepsRunes(t *testing.T) {
+	r, err := redact.New(redact.Rule{Field: "account_name", Action: redact.ActionPartial})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in, want string
+	}{
+		{in: "Jürgen Groß", want: "*******Groß"},
+		{in: "日本語の名前", want: "**語の名前"},
+		{in: "Zoë", want: "***"},
+		{in: "", want: ""},
+	}
+	for _, tt := range tests {
+		got := r.KeyValue("account_name", tt.in)
+		if got != tt.want || !utf8.ValidString(got) {
+			t.Errorf("KeyValue(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestKeyValue(t *testing.T) {
+	r := redact.Default()
+	tests := []struct {
+		key, value, want string
+	}{
+		{key: "from-account-number", value: "ACC-0001-7001", want: "*********7001"},
+		{key: "fromAccountNumber", value: "ACC-0001-7001", want: "*********7001"},
+		{key: "account_name", value: "Alice", want: redact.Mask},
+		{key: "reason", value: "card 4111111111111111", want: "card ************1111"},
+		{key: "reason", value: "retry after 20261018", want: "retry after 20261018"},
+	}
+	for _, tt := range tests {
+		if got := r.KeyValue(tt.key, tt.value); got != tt.want {
+			t.Errorf("KeyValue(%q, %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	r, err := redact.New(
+		redact.Rule{Field: "account_name"},
+		redact.Rule{Field: "bank.AccountRequest.initial_deposit_amount", Action: redact.ActionDrop},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := r.Message(&bank.AccountRequest{AccountName: "Alice", Currency: "USD", InitialDepositAmount: 10}).(map[string]any)
+	if !ok {
+		t.Fatalf("Message() returned %T", got)
+	}
+	if got["account_name"] != redact.Mask || got["currency"] != "USD" {
+		t.Errorf("Message() = %v", got)
+	}
+	if _, found := got["initial_deposit_amount"]; found {
+		t.Errorf("Message() = %v, want the dropped field left out", got)
+	}
+
+	if got := r.Message("plain"); got != "plain" {
+		t.Errorf("Message(%q) = %v", "plain", got)
+	}
+}
+
+func TestProto(t *testing.T) {
+	req := &bank.AccountRequest{AccountName: "Alice", Currency: "USD", InitialDepositAmount: 10}
+	got, ok := redact.Default().Proto(req).(*bank.AccountRequest)
+	if !ok {
+		t.Fatal("Proto() changed the message type")
+	}
+	want := &bank.AccountRequest{AccountName: redact.Mask, Currency: "USD"}
+	if !proto.Equal(got, want) {
+		t.Errorf("Proto() = %v, want %v", got, want)
+	}
+	if req.GetAccountName() != "Alice" || req.GetInitialDepositAmount() != 10 {
+		t.Errorf("Proto() modified its argument: %v", req)
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule redact.Rule
+	}{
+		{name: "empty", rule: redact.Rule{}},
+		{name: "unknown action", rule: redact.Rule{Field: "name", Action: "hash"}},
+		{name: "field and pattern", rule: redact.Rule{Field: "name", Pattern: "x"}},
+		{name: "bad pattern", rule: redact.Rule{Pattern: "("}},
+		{name: "bad field", rule: redact.Rule{Field: "["}},
+		{name: "luhn on a field", rule: redact.Rule{Field: "name", Luhn: true}},
+	}
+	for _, tt := range tests {
+		if _, err := redact.New(tt.rule); err == nil {
+			t.Errorf("%v: New(%+v) = nil error", tt.name, tt.rule)
+		}
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	r, err := redact.New(redact.Rule{Pattern: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	redact.SetDefault(r)
+	t.Cleanup(func() { redact.SetDefault(nil) })
+
+	if got := redact.Default().String("a secret"); got != "a "+redact.Mask {
+		t.Errorf("String() = %q with the replaced default", got)
+	}
+	redact.SetDefault(nil)
+	if got := redact.Default().String("a secret"); got != "a secret" {
+		t.Errorf("String() = %q after restoring the builtin rules", got)
+	}
+}
@@ -0,0 +1,187 @@
+package fakeserver
+
+import (
+	"context"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/type/date"
+	"google.golang.org/genproto/googleapis/type/datetime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Bank fakes bank.BankServiceServer on top of in-memory balances. By
+// default unknown accounts get AccountNotFound, transfers move money between
+// known accounts and fail with InsufficientFunds, and malformed currencies
+// get InvalidCurrency.
+type Bank struct {
+	GetCurrentBalance     *Method[bank.CurrentBalanceRequest, bank.CurrentBalanceResponse]
+	FetchExchangeRates    *Method[bank.ExchangeRateRequest, bank.ExchangeRateResponse]
+	SummarizeTransactions *Method[bank.Transaction, bank.TransactionSummary]
+	TransferMultiple      *Method[bank.TransferRequest, bank.TransferResponse]
+	CreateAccount         *Method[bank.AccountRequest, bank.AccountResponse]
+
+	mu       sync.Mutex
+	balances map[string]float64
+}
+
+func NewBank() *Bank {
+	b := &Bank{balances: make(map[string]float64)}
+	b.GetCurrentBalance = newMethod(b.currentBalance)
+	b.FetchExchangeRates = newMethod(exchangeRates)
+	b.SummarizeTransactions = newMethod(summarize)
+	b.TransferMultiple = newMethod(b.transfer)
+	b.CreateAccount = newMethod(b.createAccount)
+	return b
+}
+
+// SetBalance creates account if needed.
+func (b *Bank) SetBalance(account string, amount float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.balances[account] = amount
+}
+
+func (b *Bank) Balance(account string) (float64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	amount, ok := b.balances[account]
+	return amount, ok
+}
+
+func today() *date.Date {
+	now := time.Now()
+	return &date.Date{Year: int32(now.Year()), Month: int32(now.Month()), Day: int32(now.Day())}
+}
+
+func now() *datetime.DateTime {
+	t := time.Now().UTC()
+	return &datetime.DateTime{
+		Year:       int32(t.Year()),
+		Month:      int32(t.Month()),
+		Day:        int32(t.Day()),
+		Hours:      int32(t.Hour()),
+		Minutes:    int32(t.Minute()),
+		Seconds:    int32(t.Second()),
+		TimeOffset: &datetime.DateTime_UtcOffset{UtcOffset: durationpb.New(0)},
+	}
+}
+
+func (b *Bank) currentBalance(reqs []*bank.CurrentBalanceRequest) Step[bank.CurrentBalanceResponse] {
+	account := reqs[0].GetAccountNumber()
+	amount, ok := b.Balance(account)
+	if !ok {
+		return Step[bank.CurrentBalanceResponse]{Err: AccountNotFound(account)}
+	}
+	return Step[bank.CurrentBalanceResponse]{Responses: []*bank.CurrentBalanceResponse{{Amount: amount, CurrentDate: today()}}}
+}
+
+func (b *Bank) createAccount(reqs []*bank.AccountRequest) Step[bank.AccountResponse] {
+	req := reqs[0]
+	if !currencyPattern.MatchString(req.GetCurrency()) {
+		return Step[bank.AccountResponse]{Err: InvalidCurrency(req.GetCurrency())}
+	}
+	account := uuid.NewString()
+	b.SetBalance(account, req.GetInitialDepositAmount())
+	return Step[bank.AccountResponse]{Responses: []*bank.AccountResponse{{AccountUuid: account}}}
+}
+
+// exchangeRates streams StreamLength slowly rising rates.
+func exchangeRates(reqs []*bank.ExchangeRateRequest) Step[bank.ExchangeRateResponse] {
+	req := reqs[0]
+	for _, currency := range []string{req.GetFromCurrency(), req.GetToCurrency()} {
+		if !currencyPattern.MatchString(currency) {
+			return Step[bank.ExchangeRateResponse]{Err: InvalidCurrency(currency)}
+		}
+	}
+	var step Step[bank.ExchangeRateResponse]
+	for i := range StreamLength {
+		step.Responses = append(step.Responses, &bank.ExchangeRateResponse{
+			FromCurrency: req.GetFromCurrency(),
+			ToCurrency:   req.GetToCurrency(),
+			Rate:         1 + float64(i)/100,
+			Timestamp:    time.Now().Format(time.RFC3339),
+		})
+	}
+	return step
+}
+
+func summarize(reqs []*bank.Transaction) Step[bank.TransactionSummary] {
+	summary := &bank.TransactionSummary{TransactionDate: today()}
+	for _, tx := range reqs {
+		summary.AccountNumber = tx.GetAccountNumber()
+		switch tx.GetType() {
+		case bank.TransactionType_TRANSACTION_TYPE_IN:
+			summary.SumAmountIn += tx.GetAmount()
+		case bank.TransactionType_TRANSACTION_TYPE_OUT:
+			summary.SumAmountOut += tx.GetAmount()
+		}
+	}
+	summary.SumTotal = summary.SumAmountIn - summary.SumAmountOut
+	return Step[bank.TransactionSummary]{Responses: []*bank.TransactionSummary{summary}}
+}
+
+// transfer applies the transfers in order, all or nothing, and answers with
+// the last one.
+func (b *Bank) transfer(reqs []*bank.TransferRequest) Step[bank.TransferResponse] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	balances := make(map[string]float64, len(b.balances))
+	for account, amount := range b.balances {
+		balances[account] = amount
+	}
+	resp := &bank.TransferResponse{Status: bank.TransferStatus_TRANSFER_STATUS_SUCCESS, Timestamp: now()}
+	for _, req := range reqs {
+		from, to, amount := req.GetFromAccountNumber(), req.GetToAccountNumber(), float64(req.GetAmount())
+		if !currencyPattern.MatchString(req.GetCurrent()) {
+			return Step[bank.TransferResponse]{Err: InvalidCurrency(req.GetCurrent())}
+		}
+		for _, account := range []string{from, to} {
+			if _, ok := balances[account]; !ok {
+				return Step[bank.TransferResponse]{Err: AccountNotFound(account)}
+			}
+		}
+		if balances[from] < amount {
+			return Step[bank.TransferResponse]{Err: InsufficientFunds(from, balances[from], amount)}
+		}
+		balances[from] -= amount
+		balances[to] += amount
+
+		resp.FromAccountNumber, resp.ToAccountNumber = from, to
+		resp.Current, resp.Amount = req.GetCurrent(), amount
+	}
+	b.balances = balances
+	return Step[bank.TransferResponse]{Responses: []*bank.TransferResponse{resp}}
+}
+
+type bankServer struct {
+	bank.UnimplementedBankServiceServer
+	b *Bank
+}
+
+func (s bankServer) GetCurrentBalance(ctx context.Context, req *bank.CurrentBalanceRequest) (*bank.CurrentBalanceResponse, error) {
+	return s.b.GetCurrentBalance.unary(ctx, req)
+}
+
+func (s bankServer) FetchExchangeRates(req *bank.ExchangeRateRequest, stream grpc.ServerStreamingServer[bank.ExchangeRateResponse]) error {
+	return s.b.FetchExchangeRates.serverStream(req, stream)
+}
+
+func (s bankServer) SummarizeTransactions(stream grpc.ClientStreamingServer[bank.Transaction, bank.TransactionSummary]) error {
+	return s.b.SummarizeTransactions.clientStream(stream)
+}
+
+func (s bankServer) TransferMultiple(stream grpc.ClientStreamingServer[bank.TransferRequest, bank.TransferResponse]) error {
+	return s.b.TransferMultiple.clientStream(stream)
+}
+
+func (s bankServer) CreateAccount(ctx context.Context, req *bank.AccountRequest) (*bank.AccountResponse, error) {
+	return s.b.CreateAccount.unary(ctx, req)
+}
@@ -0,0 +1,89 @@
+package fakeserver
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Domain is sent in the ErrorInfo of the errors below.
+const Domain = "fakeserver.bank"
+
+// Status returns a status carrying the given errdetails messages. The error
+// is the reason a detail could not be attached, for the caller to fail its
+// test with.
+func Status(code codes.Code, msg string, details ...protoadapt.MessageV1) (*status.Status, error) {
+	st := status.New(code, msg)
+	if len(details) == 0 {
+		return st, nil
+	}
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return nil, fmt.Errorf("fakeserver: attaching details to %v status: %w", code, err)
+	}
+	return withDetails, nil
+}
+
+// Error is Status for the errors below. A detail that cannot be attached
+// makes the call fail with codes.Internal and the reason, so the test sees
+// it rather than a status lacking the detail.
+func Error(code codes.Code, msg string, details ...protoadapt.MessageV1) error {
+	st, err := Status(code, msg, details...)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return st.Err()
+}
+
+func errorInfo(reason string, metadata map[string]string) *errdetails.ErrorInfo {
+	return &errdetails.ErrorInfo{Domain: Domain, Reason: reason, Metadata: metadata}
+}
+
+// AccountNotFound is what the bank answers for an unknown account.
+func AccountNotFound(account string) error {
+	return Error(codes.NotFound, fmt.Sprintf("account %v not found", account),
+		errorInfo("ACCOUNT_NOT_FOUND", map[string]string{"account_number": account}),
+		&errdetails.ResourceInfo{ResourceType: "account", ResourceName: account},
+	)
+}
+
+// InsufficientFunds is what the bank answers for a transfer that exceeds
+// the balance of account.
+func InsufficientFunds(account string, balance, amount float64) error {
+	return Error(codes.FailedPrecondition, "insufficient funds",
+		errorInfo("INSUFFICIENT_FUNDS", map[string]string{"account_number": account}),
+		&errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{
+			Type:        "INSUFFICIENT_FUNDS",
+			Subject:     account,
+			Description: fmt.Sprintf("balance %.2f is lower than %.2f", balance, amount),
+		}}},
+	)
+}
+
+// InvalidCurrency is what the bank answers for an unsupported currency.
+func InvalidCurrency(currency string) error {
+	return Error(codes.InvalidArgument, fmt.Sprintf("invalid currency %q", currency),
+		errorInfo("INVALID_CURRENCY", map[string]string{"currency": currency}),
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       "currency",
+			Description: "currency must be a three letter ISO 4217 code",
+		}}},
+	)
+}
+
+// RateLimited asks the client to come back after retryAfter.
+func RateLimited(retryAfter time.Duration) error {
+	return Error(codes.ResourceExhausted, "rate limit exceeded",
+		errorInfo("RATE_LIMIT_EXCEEDED", nil),
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     "client",
+			Description: "too many requests",
+		}}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+}
@@ -0,0 +1,69 @@
+// Package fakeserver runs in-process fakes of the bank, hello, resiliency
+// and resiliency with metadata services over bufconn, so the adapters and
+// interceptors can be exercised without the real server.
+//
+// Every RPC is a Method whose answers can be scripted with Push. Once the
+// script ran out a default answer resembling the real server is used.
+package fakeserver
+
+import (
+	"context"
+	"net"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/bank"
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/resiliency"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufSize = 1 << 20
+
+type Server struct {
+	Bank                   *Bank
+	Hello                  *Hello
+	Resiliency             *Resiliency
+	ResiliencyWithMetadata *ResiliencyWithMetadata
+
+	lis *bufconn.Listener
+	srv *grpc.Server
+}
+
+// New starts serving all fakes. Close stops them.
+func New(opts ...grpc.ServerOption) *Server {
+	s := &Server{
+		Bank:                   NewBank(),
+		Hello:                  NewHello(),
+		Resiliency:             NewResiliency(),
+		ResiliencyWithMetadata: NewResiliencyWithMetadata(),
+		lis:                    bufconn.Listen(bufSize),
+		srv:                    grpc.NewServer(opts...),
+	}
+	bank.RegisterBankServiceServer(s.srv, bankServer{b: s.Bank})
+	hello.RegisterHelloServiceServer(s.srv, helloServer{h: s.Hello})
+	resiliency.RegisterResiliencyServiceServer(s.srv, resiliencyServer{r: s.Resiliency})
+	resiliency.RegisterResiliencyServiceWithMetadataServer(s.srv, resiliencyWithMetadataServer{r: s.ResiliencyWithMetadata})
+
+	go s.srv.Serve(s.lis)
+	return s
+}
+
+// Dial connects to the fakes without transport security. opts come after the
+// defaults and may replace them.
+func (s *Server) Dial(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	dialer := func(ctx context.Context, _ string) (net.Conn, error) {
+		return s.lis.DialContext(ctx)
+	}
+	opts = append([]grpc.DialOption{
+		grpc.WithContextDialer(dialer),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, opts...)
+	return grpc.NewClient("passthrough:///fakeserver", opts...)
+}
+
+// Close stops the server immediately, failing the calls in progress.
+func (s *Server) Close() {
+	s.srv.Stop()
+	s.lis.Close()
+}
@@ -0,0 +1,79 @@
+package fakeserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/hello"
+	"google.golang.org/grpc"
+)
+
+// Hello fakes hello.HelloServiceServer. By default every name is greeted
+// with "Hello <name>".
+type Hello struct {
+	SayHello          *Method[hello.HelloRequest, hello.HelloResponse]
+	HelloServerStream *Method[hello.HelloRequest, hello.HelloResponse]
+	HelloClientStream *Method[hello.HelloRequest, hello.HelloResponse]
+	HelloContinuous   *Method[hello.HelloRequest, hello.HelloResponse]
+}
+
+func NewHello() *Hello {
+	return &Hello{
+		SayHello:          newMethod(greetEach),
+		HelloServerStream: newMethod(greetRepeatedly),
+		HelloClientStream: newMethod(greetAll),
+		HelloContinuous:   newMethod(greetEach),
+	}
+}
+
+func greet(name string) *hello.HelloResponse {
+	return &hello.HelloResponse{Greet: "Hello " + name}
+}
+
+func greetEach(reqs []*hello.HelloRequest) Step[hello.HelloResponse] {
+	var step Step[hello.HelloResponse]
+	for _, req := range reqs {
+		step.Responses = append(step.Responses, greet(req.GetName()))
+	}
+	return step
+}
+
+func greetRepeatedly(reqs []*hello.HelloRequest) Step[hello.HelloResponse] {
+	var step Step[hello.HelloResponse]
+	for i := range StreamLength {
+		step.Responses = append(step.Responses, greet(fmt.Sprintf("%v %d", reqs[0].GetName(), i+1)))
+	}
+	return step
+}
+
+func greetAll(reqs []*hello.HelloRequest) Step[hello.HelloResponse] {
+	names := make([]string, len(reqs))
+	for i, req := range reqs {
+		names[i] = req.GetName()
+	}
+	return Step[hello.HelloResponse]{Responses: []*hello.HelloResponse{greet(strings.Join(names, ", "))}}
+}
+
+// helloServer implements the generated interface, whose methods share their
+// names with the fields of Hello.
+type helloServer struct {
+	hello.UnimplementedHelloServiceServer
+	h *Hello
+}
+
+func (s helloServer) SayHello(ctx context.Context, req *hello.HelloRequest) (*hello.HelloResponse, error) {
+	return s.h.SayHello.unary(ctx, req)
+}
+
+func (s helloServer) HelloServerStream(req *hello.HelloRequest, stream grpc.ServerStreamingServer[hello.HelloResponse]) error {
+	return s.h.HelloServerStream.serverStream(req, stream)
+}
+
+func (s helloServer) HelloClientStream(stream grpc.ClientStreamingServer[hello.HelloRequest, hello.HelloResponse]) error {
+	return s.h.HelloClientStream.clientStream(stream)
+}
+
+func (s helloServer) HelloContinuous(stream grpc.BidiStreamingServer[hello.HelloRequest, hello.HelloResponse]) error {
+	return s.h.HelloContinuous.bidiStream(stream)
+}
@@ -0,0 +1,345 @@
+package fakeserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// StreamLength is how many messages the default answers of server streams
+// send.
+const StreamLength = 10
+
+// Step is the scripted answer to one call.
+type Step[Resp any] struct {
+	// Delay is waited before anything is sent.
+	Delay time.Duration
+
+	Header  metadata.MD
+	Trailer metadata.MD
+
+	// Responses holds the single response of unary and client streaming
+	// calls, or the messages sent on server and bidirectional streams. On
+	// bidirectional streams one message is sent for each request received,
+	// the remaining ones after the client closed its side.
+	Responses []*Resp
+
+	// Interval is waited between the messages of a stream.
+	Interval time.Duration
+
+	// Err ends the call once the responses were sent. Use Error and the
+	// helpers next to it to attach error details.
+	Err error
+
+	// RecvLimit stops reading the requests of client and bidirectional
+	// streams after that many, to answer before the client is done. Zero
+	// reads until the client closes its side.
+	RecvLimit int
+}
+
+// Call is what a method received during one call.
+type Call[Req any] struct {
+	Metadata metadata.MD
+	Requests []*Req
+}
+
+// Method scripts the answers of one RPC and records its calls.
+type Method[Req, Resp any] struct {
+	mu       sync.Mutex
+	steps    []Step[Resp]
+	calls    []*Call[Req]
+	fallback func(reqs []*Req) Step[Resp]
+}
+
+func newMethod[Req, Resp any](fallback func(reqs []*Req) Step[Resp]) *Method[Req, Resp] {
+	return &Method[Req, Resp]{fallback: fallback}
+}
+
+// Push queues steps, each answers one call in order.
+func (m *Method[Req, Resp]) Push(steps ...Step[Resp]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps = append(m.steps, steps...)
+}
+
+// Respond queues a step answering with resp.
+func (m *Method[Req, Resp]) Respond(resp ...*Resp) {
+	m.Push(Step[Resp]{Responses: resp})
+}
+
+// Fail queues a step failing with err.
+func (m *Method[Req, Resp]) Fail(err error) {
+	m.Push(Step[Resp]{Err: err})
+}
+
+// SetDefault replaces the answer used once the script ran out. It receives
+// the requests of the call, for bidirectional streams it is called for each
+// request.
+func (m *Method[Req, Resp]) SetDefault(fallback func(reqs []*Req) Step[Resp]) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback = fallback
+}
+
+// Calls returns the calls received so far, oldest first.
+func (m *Method[Req, Resp]) Calls() []Call[Req] {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := make([]Call[Req], len(m.calls))
+	for i, c := range m.calls {
+		calls[i] = Call[Req]{Metadata: c.Metadata.Copy(), Requests: append([]*Req(nil), c.Requests...)}
+	}
+	return calls
+}
+
+// Reset drops the pending steps and the recorded calls.
+func (m *Method[Req, Resp]) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.steps = nil
+	m.calls = nil
+}
+
+func (m *Method[Req, Resp]) start(ctx context.Context) *Call[Req] {
+	md, _ := metadata.FromIncomingContext(ctx)
+	call := &Call[Req]{Metadata: md}
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	m.mu.Unlock()
+	return call
+}
+
+func (m *Method[Req, Resp]) received(call *Call[Req], req *Req) {
+	m.mu.Lock()
+	call.Requests = append(call.Requests, req)
+	m.mu.Unlock()
+}
+
+// pop returns the next scripted step, if any.
+func (m *Method[Req, Resp]) pop() (Step[Resp], bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.steps) == 0 {
+		return Step[Resp]{}, false
+	}
+	step := m.steps[0]
+	m.steps = m.steps[1:]
+	return step, true
+}
+
+func (m *Method[Req, Resp]) defaultStep(reqs []*Req) Step[Resp] {
+	m.mu.Lock()
+	fallback := m.fallback
+	m.mu.Unlock()
+	if fallback == nil {
+		return Step[Resp]{Err: status.Error(codes.Unimplemented, "fakeserver: nothing scripted")}
+	}
+	return fallback(reqs)
+}
+
+func (m *Method[Req, Resp]) next(reqs []*Req) Step[Resp] {
+	if step, ok := m.pop(); ok {
+		return step
+	}
+	return m.defaultStep(reqs)
+}
+
+func (m *Method[Req, Resp]) unary(ctx context.Context, req *Req) (*Resp, error) {
+	call := m.start(ctx)
+	m.received(call, req)
+	step := m.next([]*Req{req})
+
+	if err := sleep(ctx, step.Delay); err != nil {
+		return nil, err
+	}
+	if step.Header != nil {
+		grpc.SetHeader(ctx, step.Header)
+	}
+	if step.Trailer != nil {
+		grpc.SetTrailer(ctx, step.Trailer)
+	}
+	if step.Err != nil {
+		return nil, step.Err
+	}
+	return singleResponse(step)
+}
+
+type sendStream[Resp any] interface {
+	grpc.ServerStream
+	Send(*Resp) error
+}
+
+func (m *Method[Req, Resp]) serverStream(req *Req, stream sendStream[Resp]) error {
+	call := m.start(stream.Context())
+	m.received(call, req)
+	step := m.next([]*Req{req})
+
+	if err := sleep(stream.Context(), step.Delay); err != nil {
+		return err
+	}
+	if err := sendHeader(stream, step.Header); err != nil {
+		return err
+	}
+	for i, resp := range step.Responses {
+		if err := send(stream, resp, i, step.Interval); err != nil {
+			return err
+		}
+	}
+	stream.SetTrailer(step.Trailer)
+	return step.Err
+}
+
+func (m *Method[Req, Resp]) clientStream(stream grpc.ClientStreamingServer[Req, Resp]) error {
+	call := m.start(stream.Context())
+	step, scripted := m.pop()
+
+	var reqs []*Req
+	for step.RecvLimit == 0 || len(reqs) < step.RecvLimit {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		m.received(call, req)
+		reqs = append(reqs, req)
+	}
+	if !scripted {
+		step = m.defaultStep(reqs)
+	}
+
+	if err := sleep(stream.Context(), step.Delay); err != nil {
+		return err
+	}
+	if err := sendHeader(stream, step.Header); err != nil {
+		return err
+	}
+	stream.SetTrailer(step.Trailer)
+	if step.Err != nil {
+		return step.Err
+	}
+	resp, err := singleResponse(step)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+func (m *Method[Req, Resp]) bidiStream(stream grpc.BidiStreamingServer[Req, Resp]) error {
+	call := m.start(stream.Context())
+	step, scripted := m.pop()
+	if !scripted {
+		return m.bidiDefault(call, stream)
+	}
+
+	if err := sleep(stream.Context(), step.Delay); err != nil {
+		return err
+	}
+	if err := sendHeader(stream, step.Header); err != nil {
+		return err
+	}
+	sent, received := 0, 0
+	for step.RecvLimit == 0 || received < step.RecvLimit {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		m.received(call, req)
+		received++
+		if sent < len(step.Responses) {
+			if err := send(stream, step.Responses[sent], sent, step.Interval); err != nil {
+				return err
+			}
+			sent++
+		}
+	}
+	for ; sent < len(step.Responses); sent++ {
+		if err := send(stream, step.Responses[sent], sent, step.Interval); err != nil {
+			return err
+		}
+	}
+	stream.SetTrailer(step.Trailer)
+	return step.Err
+}
+
+// bidiDefault answers each request on its own. Only the header of the
+// first answer is sent.
+func (m *Method[Req, Resp]) bidiDefault(call *Call[Req], stream grpc.BidiStreamingServer[Req, Resp]) error {
+	for first := true; ; first = false {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		m.received(call, req)
+
+		step := m.defaultStep([]*Req{req})
+		if err := sleep(stream.Context(), step.Delay); err != nil {
+			return err
+		}
+		if first {
+			if err := sendHeader(stream, step.Header); err != nil {
+				return err
+			}
+		}
+		for i, resp := range step.Responses {
+			if err := send(stream, resp, i, step.Interval); err != nil {
+				return err
+			}
+		}
+		if step.Err != nil {
+			return step.Err
+		}
+	}
+}
+
+func singleResponse[Resp any](step Step[Resp]) (*Resp, error) {
+	if len(step.Responses) == 0 {
+		return nil, status.Error(codes.Internal, "fakeserver: step has neither a response nor an error")
+	}
+	return step.Responses[0], nil
+}
+
+func sendHeader(stream grpc.ServerStream, md metadata.MD) error {
+	if md == nil {
+		return nil
+	}
+	return stream.SendHeader(md)
+}
+
+func send[Resp any](stream sendStream[Resp], resp *Resp, i int, interval time.Duration) error {
+	if i > 0 {
+		if err := sleep(stream.Context(), interval); err != nil {
+			return err
+		}
+	}
+	return stream.Send(resp)
+}
+
+// sleep waits d unless the call ends first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
@@ -0,0 +1,139 @@
+package fakeserver
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/VallabhSLEPAM/go-with-grpc/protogen/go/resiliency"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ResiliencyMethod answers ResiliencyRequests.
+type ResiliencyMethod = Method[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse]
+
+// Resiliency fakes resiliency.ResiliencyServiceServer. Like the real server
+// it waits between MinDelaySecond and MaxDelaySecond by default, then fails
+// with one of the StatusCodes picked at random, 0 meaning success.
+type Resiliency struct {
+	UnaryResiliency         *ResiliencyMethod
+	ServerResiliency        *ResiliencyMethod
+	ClientResiliency        *ResiliencyMethod
+	BiDirectionalResiliency *ResiliencyMethod
+}
+
+func NewResiliency() *Resiliency {
+	return &Resiliency{
+		UnaryResiliency:         newMethod(resiliencyStep(1, nil)),
+		ServerResiliency:        newMethod(resiliencyStep(StreamLength, nil)),
+		ClientResiliency:        newMethod(resiliencyStep(1, nil)),
+		BiDirectionalResiliency: newMethod(resiliencyStep(1, nil)),
+	}
+}
+
+// ResiliencyWithMetadata fakes resiliency.ResiliencyServiceWithMetadataServer
+// the same way as Resiliency. Its default answers also send the
+// grpc-server-time, grpc-server-os and grpc-server-host headers.
+type ResiliencyWithMetadata struct {
+	UnaryResiliencyWithMetadata         *ResiliencyMethod
+	ServerResiliencyWithMetadata        *ResiliencyMethod
+	ClientResiliencyWithMetadata        *ResiliencyMethod
+	BiDirectionalResiliencyWithMetadata *ResiliencyMethod
+}
+
+func NewResiliencyWithMetadata() *ResiliencyWithMetadata {
+	return &ResiliencyWithMetadata{
+		UnaryResiliencyWithMetadata:         newMethod(resiliencyStep(1, serverMetadata)),
+		ServerResiliencyWithMetadata:        newMethod(resiliencyStep(StreamLength, serverMetadata)),
+		ClientResiliencyWithMetadata:        newMethod(resiliencyStep(1, serverMetadata)),
+		BiDirectionalResiliencyWithMetadata: newMethod(resiliencyStep(1, serverMetadata)),
+	}
+}
+
+func serverMetadata() metadata.MD {
+	host, _ := os.Hostname()
+	return metadata.Pairs(
+		"grpc-server-time", time.Now().Format(time.RFC3339),
+		"grpc-server-os", runtime.GOOS,
+		"grpc-server-host", host,
+	)
+}
+
+// resiliencyStep answers the last request with count responses. header, if
+// set, builds the response headers.
+func resiliencyStep(count int, header func() metadata.MD) func([]*resiliency.ResiliencyRequest) Step[resiliency.ResiliencyResponse] {
+	return func(reqs []*resiliency.ResiliencyRequest) Step[resiliency.ResiliencyResponse] {
+		var req *resiliency.ResiliencyRequest
+		if len(reqs) > 0 {
+			req = reqs[len(reqs)-1]
+		}
+
+		var step Step[resiliency.ResiliencyResponse]
+		if header != nil {
+			step.Header = header()
+		}
+		minDelay, maxDelay := req.GetMinDelaySecond(), req.GetMaxDelaySecond()
+		if maxDelay > minDelay {
+			minDelay += rand.Int32N(maxDelay - minDelay + 1)
+		}
+		step.Delay = time.Duration(minDelay) * time.Second
+
+		if codeList := req.GetStatusCodes(); len(codeList) > 0 {
+			if code := codes.Code(codeList[rand.IntN(len(codeList))]); code != codes.OK {
+				step.Err = status.Errorf(code, "fakeserver: failing with %v as requested", code)
+				return step
+			}
+		}
+		for range count {
+			step.Responses = append(step.Responses, &resiliency.ResiliencyResponse{DummyString: "fakeserver response"})
+		}
+		return step
+	}
+}
+
+type resiliencyServer struct {
+	resiliency.UnimplementedResiliencyServiceServer
+	r *Resiliency
+}
+
+func (s resiliencyServer) UnaryResiliency(ctx context.Context, req *resiliency.ResiliencyRequest) (*resiliency.ResiliencyResponse, error) {
+	return s.r.UnaryResiliency.unary(ctx, req)
+}
+
+func (s resiliencyServer) ServerResiliency(req *resiliency.ResiliencyRequest, stream grpc.ServerStreamingServer[resiliency.ResiliencyResponse]) error {
+	return s.r.ServerResiliency.serverStream(req, stream)
+}
+
+func (s resiliencyServer) ClientResiliency(stream grpc.ClientStreamingServer[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse]) error {
+	return s.r.ClientResiliency.clientStream(stream)
+}
+
+func (s resiliencyServer) BiDirectionalResiliency(stream grpc.BidiStreamingServer[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse]) error {
+	return s.r.BiDirectionalResiliency.bidiStream(stream)
+}
+
+type resiliencyWithMetadataServer struct {
+	resiliency.UnimplementedResiliencyServiceWithMetadataServer
+	r *ResiliencyWithMetadata
+}
+
+func (s resiliencyWithMetadataServer) UnaryResiliencyWithMetadata(ctx context.Context, req *resiliency.ResiliencyRequest) (*resiliency.ResiliencyResponse, error) {
+	return s.r.UnaryResiliencyWithMetadata.unary(ctx, req)
+}
+
+func (s resiliencyWithMetadataServer) ServerResiliencyWithMetadata(req *resiliency.ResiliencyRequest, stream grpc.ServerStreamingServer[resiliency.ResiliencyResponse]) error {
+	return s.r.ServerResiliencyWithMetadata.serverStream(req, stream)
+}
+
+func (s resiliencyWithMetadataServer) ClientResiliencyWithMetadata(stream grpc.ClientStreamingServer[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse]) error {
+	return s.r.ClientResiliencyWithMetadata.clientStream(stream)
+}
+
+func (s resiliencyWithMetadataServer) BiDirectionalResiliencyWithMetadata(stream grpc.BidiStreamingServer[resiliency.ResiliencyRequest, resiliency.ResiliencyResponse]) error {
+	return s.r.BiDirectionalResiliencyWithMetadata.bidiStream(stream)
+}
@@ -0,0 +1,189 @@
+package tlsconfig
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// ReloadEvent reports the outcome of a reload attempt. Err is nil on success.
+type ReloadEvent struct {
+	Time time.Time
+	Err  error
+}
+
+// fileStamp identifies a version of a file. A missing or unreadable file
+// gets a stamp too, so it is reported once rather than on every poll.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	missing bool
+}
+
+// Reloader keeps client credentials in sync with the CA, certificate and
+// key files of Options. Only new handshakes pick up reloaded files; existing
+// connections keep the credentials they were established with. A failed
+// reload keeps the last good credentials.
+type Reloader struct {
+	opts     Options
+	onReload func(ReloadEvent)
+
+	mu     sync.RWMutex
+	creds  credentials.TransportCredentials
+	stamps map[string]fileStamp
+	last   ReloadEvent
+}
+
+// NewReloader loads the files once and fails if they are unusable. onReload,
+// when not nil, is called after every reload attempt made by Run or Reload.
+func NewReloader(opts Options, onReload func(ReloadEvent)) (*Reloader, error) {
+	r := &Reloader{
+		opts:     opts,
+		onReload: onReload,
+	}
+	creds, stamps, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.creds = creds
+	r.stamps = stamps
+	r.last = ReloadEvent{Time: time.Now()}
+	return r, nil
+}
+
+// Credentials returns transport credentials that always handshake with the
+// most recently loaded files.
+func (r *Reloader) Credentials() credentials.TransportCredentials {
+	return &reloadingCredentials{reloader: r}
+}
+
+// Run polls the files every interval and reloads them when one changed,
+// until ctx is done.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.changed() {
+				r.Reload()
+			}
+		}
+	}
+}
+
+// Reload loads the files now, regardless of whether they changed.
+func (r *Reloader) Reload() error {
+	creds, stamps, err := r.load()
+
+	event := ReloadEvent{Time: time.Now(), Err: err}
+	r.mu.Lock()
+	if err == nil {
+		r.creds = creds
+	}
+	// Remember broken files too so they are only retried once they change.
+	r.stamps = stamps
+	r.last = event
+	r.mu.Unlock()
+
+	if r.onReload != nil {
+		r.onReload(event)
+	}
+	return err
+}
+
+// LastReload returns the outcome of the most recent load.
+func (r *Reloader) LastReload() ReloadEvent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.last
+}
+
+func (r *Reloader) current() credentials.TransportCredentials {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.creds
+}
+
+func (r *Reloader) load() (credentials.TransportCredentials, map[string]fileStamp, error) {
+	// Stat first so a file replaced while loading is picked up next time.
+	stamps := r.stat()
+	creds, err := NewClientCredentials(r.opts)
+	if err != nil {
+		return nil, stamps, err
+	}
+	return creds, stamps, nil
+}
+
+func (r *Reloader) stat() map[string]fileStamp {
+	stamps := make(map[string]fileStamp)
+	for _, path := range []string{r.opts.CAFile, r.opts.CertFile, r.opts.KeyFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			// Loading reports the error.
+			stamps[path] = fileStamp{missing: true}
+			continue
+		}
+		stamps[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps
+}
+
+func (r *Reloader) changed() bool {
+	stamps := r.stat()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for path, stamp := range stamps {
+		if r.stamps[path] != stamp {
+			return true
+		}
+	}
+	return false
+}
+
+type reloadingCredentials struct {
+	reloader   *Reloader
+	serverName string
+}
+
+func (c *reloadingCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	creds := c.reloader.current()
+	if c.serverName != "" {
+		creds = creds.Clone()
+		creds.OverrideServerName(c.serverName)
+	}
+	return creds.ClientHandshake(ctx, authority, conn)
+}
+
+func (c *reloadingCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("tlsconfig: reloading credentials are client side only")
+}
+
+func (c *reloadingCredentials) Info() credentials.ProtocolInfo {
+	return c.reloader.current().Info()
+}
+
+func (c *reloadingCredentials) Clone() credentials.TransportCredentials {
+	return &reloadingCredentials{
+		reloader:   c.reloader,
+		serverName: c.serverName,
+	}
+}
+
+// Deprecated: use the ServerName option instead.
+func (c *reloadingCredentials) OverrideServerName(serverName string) error {
+	c.serverName = serverName
+	return nil
+}
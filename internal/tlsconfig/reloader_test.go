@@ -0,0 +1,141 @@
+package tlsconfig
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// clientHandshake runs the handshake of creds over a new connection to addr.
+func clientHandshake(t *testing.T, r *Reloader, addr string) error {
+	t.Helper()
+	raw, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	raw.SetDeadline(time.Now().Add(5 * time.Second))
+
+	conn, _, err := r.Credentials().ClientHandshake(context.Background(), addr, raw)
+	if err != nil {
+		return err
+	}
+	_, err = conn.Read(make([]byte, 1))
+	return err
+}
+
+func waitHandshake(t *testing.T, results <-chan serverHandshake) serverHandshake {
+	t.Helper()
+	select {
+	case res := <-results:
+		return res
+	case <-time.After(5 * time.Second):
+		t.Fatal("server handshake did not finish")
+		return serverHandshake{}
+	}
+}
+
+func TestReloaderPicksUpRotatedCertificate(t *testing.T) {
+	ca := newCert(t, "test-ca", nil)
+	server := newCert(t, "server.test", ca)
+	oldClient := newCert(t, "client", ca)
+	newClient := newCert(t, "client", ca)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	writeFile(t, caFile, ca.certPEM())
+	certFile, keyFile := oldClient.writeFiles(t, dir, "client")
+	addr, results := startServer(t, server, ca)
+
+	reloads := make(chan ReloadEvent, 10)
+	r, err := NewReloader(Options{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "server.test"},
+		func(event ReloadEvent) { reloads <- event })
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx, 10*time.Millisecond)
+
+	if err := clientHandshake(t, r, addr); err != nil {
+		t.Fatalf("handshake before rotation: %v", err)
+	}
+	if res := waitHandshake(t, results); !res.peer.Equal(oldClient.cert) {
+		t.Fatal("server did not see the initial client certificate")
+	}
+
+	newClient.writeFiles(t, dir, "client")
+	// Make the change visible even on coarse modification times.
+	later := time.Now().Add(time.Minute)
+	for _, path := range []string{certFile, keyFile} {
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case event := <-reloads:
+		if event.Err != nil {
+			t.Fatalf("reload failed: %v", event.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("rotated files were not reloaded")
+	}
+
+	if err := clientHandshake(t, r, addr); err != nil {
+		t.Fatalf("handshake after rotation: %v", err)
+	}
+	if res := waitHandshake(t, results); !res.peer.Equal(newClient.cert) {
+		t.Error("server did not see the rotated client certificate")
+	}
+}
+
+func TestReloaderReportsMissingFileOnce(t *testing.T) {
+	ca := newCert(t, "test-ca", nil)
+	client := newCert(t, "client", ca)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	writeFile(t, caFile, ca.certPEM())
+	certFile, keyFile := client.writeFiles(t, dir, "client")
+
+	reloads := make(chan ReloadEvent, 100)
+	r, err := NewReloader(Options{CAFile: caFile, CertFile: certFile, KeyFile: keyFile},
+		func(event ReloadEvent) { reloads <- event })
+	if err != nil {
+		t.Fatalf("NewReloader: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx, 5*time.Millisecond)
+
+	if err := os.Remove(keyFile); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-reloads:
+		if event.Err == nil {
+			t.Fatal("reload without the key file succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("missing key file was not reported")
+	}
+
+	// Many polls later the missing file is still reported only once.
+	time.Sleep(100 * time.Millisecond)
+	if n := len(reloads); n != 0 {
+		t.Errorf("got %v more reload attempts for an unchanged missing file, want 0", n)
+	}
+
+	client.writeFiles(t, dir, "client")
+	select {
+	case event := <-reloads:
+		if event.Err != nil {
+			t.Fatalf("reload after restoring the key failed: %v", event.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("restored key file was not reloaded")
+	}
+}
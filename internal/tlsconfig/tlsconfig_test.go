@@ -0,0 +1,227 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newCert issues a certificate for cn, signed by parent or self-signed as
+// a CA when parent is nil.
+func newCert(t *testing.T, cn string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{cn},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der})
+}
+
+func (c *testCert) keyPEM(t *testing.T) []byte {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+// writeFiles writes the PEM files of c into dir and returns their paths.
+func (c *testCert) writeFiles(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	writeFile(t, certFile, c.certPEM())
+	writeFile(t, keyFile, c.keyPEM(t))
+	return certFile, keyFile
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serverHandshake is the outcome of a handshake seen by the server, with the
+// client certificate on success.
+type serverHandshake struct {
+	err  error
+	peer *x509.Certificate
+}
+
+// startServer accepts TLS connections requiring a client certificate
+// issued by clientCA, and sends the handshake outcome of each to the
+// returned channel.
+func startServer(t *testing.T, server *testCert, clientCA *testCert) (string, <-chan serverHandshake) {
+	t.Helper()
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCA.cert)
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{server.tlsCertificate()},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		// gRPC credentials insist on HTTP/2 being negotiated.
+		NextProtos: []string{"h2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	results := make(chan serverHandshake, 10)
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				tlsConn := conn.(*tls.Conn)
+				if err := tlsConn.Handshake(); err != nil {
+					results <- serverHandshake{err: err}
+					return
+				}
+				_, err := conn.Write([]byte{1})
+				results <- serverHandshake{err: err, peer: tlsConn.ConnectionState().PeerCertificates[0]}
+			}()
+		}
+	}()
+	return lis.Addr().String(), results
+}
+
+// handshake connects with cfg and reads a byte, since with TLS 1.3 a
+// rejected client certificate only shows after the handshake.
+func handshake(addr string, cfg *tls.Config) (*tls.Conn, error) {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func TestNewClientConfigHandshake(t *testing.T) {
+	ca := newCert(t, "test-ca", nil)
+	otherCA := newCert(t, "other-ca", nil)
+	server := newCert(t, "server.test", ca)
+	client := newCert(t, "client", ca)
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.crt")
+	writeFile(t, caFile, ca.certPEM())
+	otherCAFile := filepath.Join(dir, "other-ca.crt")
+	writeFile(t, otherCAFile, otherCA.certPEM())
+	certFile, keyFile := client.writeFiles(t, dir, "client")
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantClient bool
+		wantServer bool
+	}{
+		{
+			name:       "mutual TLS",
+			opts:       Options{CAFile: caFile, CertFile: certFile, KeyFile: keyFile, ServerName: "server.test"},
+			wantClient: true,
+			wantServer: true,
+		},
+		{
+			name: "wrong CA",
+			opts: Options{CAFile: otherCAFile, CertFile: certFile, KeyFile: keyFile, ServerName: "server.test"},
+		},
+		{
+			name: "missing client certificate",
+			opts: Options{CAFile: caFile, ServerName: "server.test"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, results := startServer(t, server, ca)
+			cfg, err := NewClientConfig(tt.opts)
+			if err != nil {
+				t.Fatalf("NewClientConfig: %v", err)
+			}
+
+			conn, err := handshake(addr, cfg)
+			if (err == nil) != tt.wantClient {
+				t.Errorf("client handshake error = %v, want success %v", err, tt.wantClient)
+			}
+			if conn != nil {
+				conn.Close()
+			}
+			select {
+			case res := <-results:
+				if (res.err == nil) != tt.wantServer {
+					t.Errorf("server handshake error = %v, want success %v", res.err, tt.wantServer)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("server handshake did not finish")
+			}
+		})
+	}
+}
+
+func TestNewClientConfigRequiresCertAndKey(t *testing.T) {
+	if _, err := NewClientConfig(Options{CertFile: "client.crt"}); err == nil {
+		t.Error("NewClientConfig with a certificate but no key succeeded")
+	}
+}